@@ -0,0 +1,271 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Stable codes carried by BumpError, also used for the CLI's JSON output.
+const (
+	ErrCodeInvalidArguments = "invalid_arguments"
+	ErrCodeWorktree         = "worktree_failed"
+	ErrCodeUncommitted      = "uncommitted_changes"
+	ErrCodeHead             = "head_failed"
+	ErrCodeTagLookup        = "tag_lookup_failed"
+	ErrCodeRepeatedHash     = "repeated_hash"
+	ErrCodeIncrement        = "increment_failed"
+	ErrCodeTagCreate        = "tag_create_failed"
+	ErrCodeSignOff          = "sign_off_missing"
+	ErrCodeLine             = "line_violation"
+)
+
+// BumpError is returned by Bump with a stable code describing which step
+// failed.
+type BumpError struct {
+	Code string
+	Err  error
+}
+
+func (e *BumpError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BumpError) Unwrap() error {
+	return e.Err
+}
+
+func bumpErr(code string, format string, args ...interface{}) error {
+	return &BumpError{Code: code, Err: fmt.Errorf(format, args...)}
+}
+
+// ErrorCode returns the code of a BumpError in err's chain, or "" if there is
+// none.
+func ErrorCode(err error) string {
+	var be *BumpError
+	if errors.As(err, &be) {
+		return be.Code
+	}
+	return ""
+}
+
+// WorktreeStatus is implemented by tag sources that can tell whether there
+// are uncommitted changes.
+type WorktreeStatus interface {
+	IsClean() (bool, error)
+}
+
+// BumpOptions controls Bump. The zero value, apart from Commands, matches
+// the CLI defaults.
+type BumpOptions struct {
+	// Commands are the increments to apply, e.g. []string{"patch", "test"}.
+	Commands []string
+	// Mode is the naming mode: auto, semver, legacy or arraneous. Empty
+	// means auto.
+	Mode string
+	// AllowBackwards lets numeric commands decrease counters.
+	AllowBackwards bool
+	// SkipForwards bumps the patch when numeric commands go backwards.
+	SkipForwards bool
+	// Repeating allows tagging a commit that already carries the previous
+	// tag of the same kind.
+	Repeating bool
+	// RequireClean refuses to tag when the source reports uncommitted
+	// changes.
+	RequireClean bool
+	// Transitions are the stages allowed to follow each stage of a
+	// pre-release. Nil means DefaultStageTransitions.
+	Transitions StageTransitions
+	// RequireSignOff refuses to tag unless HEAD already carries the
+	// preceding environment's tag, see CheckSignOff. Force does not lift it.
+	RequireSignOff bool
+	// Line restricts the bump to a maintenance line: only its tags are
+	// considered and commands that would leave it are refused.
+	Line *Line
+	// Force implies AllowBackwards and Repeating, disables RequireClean and
+	// skips the stage transition check.
+	Force bool
+	// Dry computes the next tag without creating it.
+	Dry bool
+	// Message is the annotated tag message; it defaults to the tag name.
+	Message string
+	// Tagger signs the annotated tag. Nil creates a lightweight tag.
+	Tagger *Signature
+	// Explain, when set, receives why the highest tag was chosen and each
+	// decision made while incrementing it.
+	Explain io.Writer
+	// MaxAttempts bounds how often Bump recomputes the tag when another
+	// process creates it first. Zero means DefaultMaxAttempts.
+	MaxAttempts int
+}
+
+// DefaultMaxAttempts is the number of attempts Bump makes when the tag it
+// computed is created concurrently.
+const DefaultMaxAttempts = 10
+
+// BumpResult describes what Bump did. On error it holds whatever was worked
+// out before the failing step.
+type BumpResult struct {
+	// Previous is the highest tag before the bump.
+	Previous *Tag
+	// Tag is the new tag.
+	Tag *Tag
+	// Target is the commit that was, or would be, tagged.
+	Target string
+	// Created reports whether the tag was created.
+	Created bool
+	// Retries counts how often the tag was recomputed because another
+	// process created it first.
+	Retries int
+}
+
+// Bump finds the highest version tag in src, applies the commands and tags
+// HEAD with the result. It refuses to repeat the previous tag of the same
+// kind on the same commit and to move counters backwards unless the options
+// allow it. When another process creates the computed tag first, Bump starts
+// again from the new highest tag, up to MaxAttempts times.
+func Bump(ctx context.Context, src TagSource, opts BumpOptions) (BumpResult, error) {
+	var result BumpResult
+	mode := opts.Mode
+	if mode == "" {
+		mode = "auto"
+	}
+	if opts.Force {
+		opts.AllowBackwards = true
+		opts.Repeating = true
+		opts.RequireClean = false
+	}
+
+	parser := SourceParser(src)
+	flags := parser.CommandsToFlags(opts.Commands, mode)
+	if !flags.Valid || flags.Empty() {
+		return result, bumpErr(ErrCodeInvalidArguments, "invalid or missing commands: %s", strings.Join(opts.Commands, " "))
+	}
+	if opts.Line != nil {
+		if err := checkLine(opts.Line, flags); err != nil {
+			return result, &BumpError{Code: ErrCodeLine, Err: err}
+		}
+		explainf(opts.Explain, "only considering tags on line %s", opts.Line)
+		src = NewLineTagSource(src, opts.Line)
+	}
+
+	if opts.RequireClean {
+		ws, ok := src.(WorktreeStatus)
+		if !ok {
+			return result, bumpErr(ErrCodeWorktree, "tag source cannot report worktree status")
+		}
+		clean, err := ws.IsClean()
+		if err != nil {
+			return result, bumpErr(ErrCodeWorktree, "failed to get worktree status: %w", err)
+		}
+		if !clean {
+			return result, bumpErr(ErrCodeUncommitted, "there are uncommitted changes in this repo")
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return result, err
+	}
+
+	currentHash, err := GetHash(src, nil)
+	if err != nil {
+		return result, bumpErr(ErrCodeHead, "failed to get current hash: %w", err)
+	}
+	result.Target = currentHash
+
+	attempts := opts.MaxAttempts
+	if attempts <= 0 {
+		attempts = DefaultMaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		err := bumpOnce(ctx, src, opts, flags, mode, &result)
+		if errors.Is(err, ErrTagExists) && attempt < attempts {
+			// another process created the tag first, start again from it
+			explainf(opts.Explain, "%s was created by another process, starting again", result.Tag)
+			result.Retries++
+			continue
+		}
+		return result, err
+	}
+}
+
+// bumpOnce runs the repeat check, finds the highest tag and creates the next
+// one, filling in result as it goes.
+func bumpOnce(ctx context.Context, src TagSource, opts BumpOptions, flags CmdFlags, mode string, result *BumpResult) error {
+	currentHash := result.Target
+	if !opts.Repeating && currentHash != "" {
+		lastSimilar, err := FindHighestSimilarVersionTag(src, mode, flags.Env)
+		if err != nil {
+			return bumpErr(ErrCodeTagLookup, "failed to find highest similar version tag: %w", err)
+		}
+		lastSimilarHash, err := GetHash(src, lastSimilar)
+		if err != nil {
+			return bumpErr(ErrCodeTagLookup, "failed to get hash for similar version: %w", err)
+		}
+		if lastSimilarHash == "" {
+			explainf(opts.Explain, "repeat check: no earlier tag of this kind")
+		} else {
+			explainf(opts.Explain, "repeat check: the last tag of this kind is %s on %s, HEAD is %s", lastSimilar, lastSimilarHash, currentHash)
+		}
+		if len(lastSimilarHash) > 0 && lastSimilarHash == currentHash {
+			return bumpErr(ErrCodeRepeatedHash, "Hash is the same for this and previous tag: (%s) %s and %s", lastSimilar, lastSimilarHash, currentHash)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	highest, err := ExplainHighestVersionTag(src, mode, opts.Explain)
+	if err != nil {
+		return bumpErr(ErrCodeTagLookup, "failed to find highest version tag: %w", err)
+	}
+	result.Previous = highest.Clone()
+	if opts.Line != nil && !opts.Line.Contains(highest) {
+		return &BumpError{Code: ErrCodeLine, Err: &LineError{Line: opts.Line}}
+	}
+
+	err = highest.IncrementWithOptions(flags, IncrementOptions{
+		AllowBackwards: opts.AllowBackwards,
+		SkipForwards:   opts.SkipForwards,
+		Transitions:    opts.Transitions,
+		Force:          opts.Force,
+		Qualifiers:     SourceParser(src).Qualifiers(),
+		Explain:        opts.Explain,
+	})
+	if err != nil {
+		return &BumpError{Code: ErrCodeIncrement, Err: err}
+	}
+	result.Tag = highest
+	if opts.RequireSignOff {
+		if err := CheckSignOff(src, highest, currentHash); err != nil {
+			return &BumpError{Code: ErrCodeSignOff, Err: err}
+		}
+	}
+	if opts.Dry {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var createOpts *CreateTagOptions
+	if opts.Tagger != nil {
+		msg := opts.Message
+		if msg == "" {
+			msg = highest.String()
+		}
+		createOpts = &CreateTagOptions{Message: msg, Tagger: opts.Tagger}
+	}
+	if err := src.CreateTag(highest.String(), currentHash, createOpts); err != nil {
+		return bumpErr(ErrCodeTagCreate, "failed to create tag: %w", err)
+	}
+	result.Created = true
+	return nil
+}
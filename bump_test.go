@@ -0,0 +1,255 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestBump(t *testing.T) {
+	ctx := context.Background()
+	tagger := &Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+
+	src := NewMemoryTagSource("c1")
+	res, err := Bump(ctx, src, BumpOptions{Commands: []string{"test"}, Tagger: tagger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Previous.String() != "v0.0.0" || res.Tag.String() != "v0.0.1-test.01" || res.Target != "c1" || !res.Created {
+		t.Errorf("unexpected first bump %+v", res)
+	}
+	if h, err := src.ResolveTag("v0.0.1-test.01"); err != nil || h != "c1" {
+		t.Errorf("tag not created: %s %v", h, err)
+	}
+
+	_, err = Bump(ctx, src, BumpOptions{Commands: []string{"test"}})
+	if code := ErrorCode(err); code != ErrCodeRepeatedHash {
+		t.Errorf("expected %s, got %q (%v)", ErrCodeRepeatedHash, code, err)
+	}
+
+	res, err = Bump(ctx, src, BumpOptions{Commands: []string{"uat"}, Dry: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag.String() != "v0.0.1-uat.01" || res.Created {
+		t.Errorf("unexpected dry run %+v", res)
+	}
+	if _, err := src.ResolveTag("v0.0.1-uat.01"); err == nil {
+		t.Errorf("dry run created a tag")
+	}
+
+	src.SetHead("c2")
+	if _, err := Bump(ctx, src, BumpOptions{Commands: []string{"test"}}); err != nil {
+		t.Fatal(err)
+	}
+	src.SetHead("c3")
+	_, err = Bump(ctx, src, BumpOptions{Commands: []string{"test1"}})
+	if code := ErrorCode(err); code != ErrCodeIncrement {
+		t.Errorf("expected %s, got %q (%v)", ErrCodeIncrement, code, err)
+	}
+	res, err = Bump(ctx, src, BumpOptions{Commands: []string{"test1"}, SkipForwards: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag.String() != "v0.0.2-test.01" {
+		t.Errorf("skip forwards got %s", res.Tag)
+	}
+
+	_, err = Bump(ctx, src, BumpOptions{Commands: []string{"bogus"}})
+	if code := ErrorCode(err); code != ErrCodeInvalidArguments {
+		t.Errorf("expected %s, got %q (%v)", ErrCodeInvalidArguments, code, err)
+	}
+	_, err = Bump(ctx, src, BumpOptions{Commands: []string{"patch"}, RequireClean: true})
+	if code := ErrorCode(err); code != ErrCodeWorktree {
+		t.Errorf("expected %s, got %q (%v)", ErrCodeWorktree, code, err)
+	}
+
+	res, err = Bump(ctx, src, BumpOptions{Commands: []string{"test"}, Force: true, Mode: ModeLegacy})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag.String() != "v0.0.2-test02" {
+		t.Errorf("forced repeat got %s", res.Tag)
+	}
+
+	src = NewMemoryTagSource("c4")
+	if err := src.CreateTag("v1.0.0-rc.03", "c3", nil); err != nil {
+		t.Fatal(err)
+	}
+	_, err = Bump(ctx, src, BumpOptions{Commands: []string{"alpha"}})
+	var te *TransitionError
+	if code := ErrorCode(err); code != ErrCodeIncrement || !errors.As(err, &te) {
+		t.Errorf("expected a transition error, got %q (%v)", code, err)
+	}
+	res, err = Bump(ctx, src, BumpOptions{Commands: []string{"alpha"}, Transitions: StageTransitions{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag.String() != "v1.0.1-alpha.01" {
+		t.Errorf("unrestricted transition got %s", res.Tag)
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := Bump(cancelled, src, BumpOptions{Commands: []string{"patch"}}); err == nil {
+		t.Errorf("expected error for cancelled context")
+	}
+}
+
+func TestBumpRepeatedHashNonCanonical(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name string
+		tag  string
+		mode string
+	}{
+		{"non-canonical name", "v1.0.0-test-1", ""},
+		{"mode rewritten", "v1.0.0-test.01", ModeLegacy},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			src := NewMemoryTagSource("c1")
+			if err := src.CreateTag(tc.tag, "c1", nil); err != nil {
+				t.Fatal(err)
+			}
+			_, err := Bump(ctx, src, BumpOptions{Commands: []string{"test"}, Mode: tc.mode})
+			if code := ErrorCode(err); code != ErrCodeRepeatedHash {
+				t.Errorf("expected %s, got %q (%v)", ErrCodeRepeatedHash, code, err)
+			}
+			if refs, _ := src.Tags(); len(refs) != 1 {
+				t.Errorf("a second tag was created on the same commit: %v", refs)
+			}
+		})
+	}
+}
+
+func TestBumpConcurrent(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "f"), []byte("one"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("f"); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+	if _, err := w.Commit("one", &git.CommitOptions{Author: sig}); err != nil {
+		t.Fatal(err)
+	}
+
+	// each job opens the repository itself, as separate CI runs would
+	const jobs = 6
+	var wg sync.WaitGroup
+	results := make([]BumpResult, jobs)
+	errs := make([]error, jobs)
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			r, err := git.PlainOpen(dir)
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			results[i], errs[i] = Bump(context.Background(), NewGoGitTagSource(r), BumpOptions{
+				Commands:    []string{"test"},
+				Repeating:   true,
+				MaxAttempts: jobs,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	seen := map[string]bool{}
+	for i := 0; i < jobs; i++ {
+		if errs[i] != nil {
+			t.Fatalf("job %d: %v", i, errs[i])
+		}
+		name := results[i].Tag.String()
+		if seen[name] {
+			t.Errorf("%s was reported by two jobs", name)
+		}
+		seen[name] = true
+	}
+	refs, err := NewGoGitTagSource(r).Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(refs) != jobs {
+		t.Errorf("expected %d tags, got %v", jobs, refs)
+	}
+	for name := range seen {
+		if _, err := r.Tag(name); err != nil {
+			t.Errorf("%s missing: %v", name, err)
+		}
+	}
+
+	// a lock left behind by another process is reported rather than ignored
+	defer func(d time.Duration) { RefLockTimeout = d }(RefLockTimeout)
+	RefLockTimeout = 20 * time.Millisecond
+	if err := os.WriteFile(filepath.Join(dir, ".git", "refs", "tags", "v9.0.0.lock"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	head, err := NewGoGitTagSource(r).Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewGoGitTagSource(r).CreateTag("v9.0.0", head, nil); !errors.Is(err, ErrTagLocked) {
+		t.Errorf("expected ErrTagLocked, got %v", err)
+	}
+}
+
+func TestBumpRetry(t *testing.T) {
+	src := &racingSource{MemoryTagSource: NewMemoryTagSource("c1"), steal: 2}
+	res, err := Bump(context.Background(), src, BumpOptions{Commands: []string{"patch"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag.String() != "v0.0.3" || res.Retries != 2 {
+		t.Errorf("got %s after %d retries", res.Tag, res.Retries)
+	}
+
+	src = &racingSource{MemoryTagSource: NewMemoryTagSource("c1"), steal: 5}
+	_, err = Bump(context.Background(), src, BumpOptions{Commands: []string{"patch"}, MaxAttempts: 3})
+	if !errors.Is(err, ErrTagExists) || ErrorCode(err) != ErrCodeTagCreate {
+		t.Errorf("expected %s wrapping ErrTagExists, got %v", ErrCodeTagCreate, err)
+	}
+}
+
+// racingSource creates each requested tag on another commit itself, as a
+// concurrent job would, until steal runs out.
+type racingSource struct {
+	*MemoryTagSource
+	steal int
+}
+
+func (s *racingSource) CreateTag(name, target string, opts *CreateTagOptions) error {
+	if s.steal > 0 {
+		s.steal--
+		if err := s.MemoryTagSource.CreateTag(name, "other", nil); err != nil {
+			return err
+		}
+		return fmt.Errorf("%s: %w", name, ErrTagExists)
+	}
+	return s.MemoryTagSource.CreateTag(name, target, opts)
+}
@@ -0,0 +1,308 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// cacheFormat is bumped whenever the cache layout or the meaning of its
+// contents changes, which discards existing caches.
+const cacheFormat = 3
+
+// TagCachePath is where CachedTagSource keeps its cache, relative to the
+// repository's .git directory.
+var TagCachePath = path.Join("git-tag-inc", "tag-cache")
+
+type fileState struct {
+	Size    int64
+	ModTime int64
+}
+
+// refState captures packed-refs and every loose tag ref so a cache can tell
+// whether any tag was added, moved or removed since it was written.
+type refState struct {
+	Packed fileState
+	Loose  map[string]fileState
+}
+
+func (a refState) equal(b refState) bool {
+	if a.Packed != b.Packed || len(a.Loose) != len(b.Loose) {
+		return false
+	}
+	for name, st := range a.Loose {
+		if other, ok := b.Loose[name]; !ok || other != st {
+			return false
+		}
+	}
+	return true
+}
+
+type cacheEntry struct {
+	Name   string
+	Hash   string
+	Commit string
+	// Tag is the parsed version, or nil when Name is not a version tag.
+	Tag *Tag
+}
+
+// tagCache is stored as JSON rather than gob because gob flattens pointers
+// and would lose the difference between a nil and a zero counter.
+type tagCache struct {
+	Format int
+	// Qualifiers records the qualifiers the tags were parsed with.
+	Qualifiers string
+	State      refState
+	Entries    []cacheEntry
+}
+
+// CachedTagSource is a GoGitTagSource that keeps every tag's parsed version
+// and resolved commit in a cache file inside .git. The cache is reused while
+// packed-refs and the loose tag refs are unchanged; otherwise only tags that
+// are new or have moved are parsed and resolved again.
+type CachedTagSource struct {
+	*GoGitTagSource
+	fs billy.Filesystem
+
+	mu      sync.Mutex
+	loaded  bool
+	entries []cacheEntry
+	byName  map[string]int
+}
+
+var _ ParsedTagSource = (*CachedTagSource)(nil)
+
+// NewCachedTagSource returns a cached source for a repository stored on disk.
+func NewCachedTagSource(r *git.Repository) (*CachedTagSource, error) {
+	s, ok := r.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, errors.New("the tag cache requires an on-disk repository")
+	}
+	return &CachedTagSource{GoGitTagSource: NewGoGitTagSource(r), fs: s.Filesystem()}, nil
+}
+
+func (c *CachedTagSource) statFile(name string) (fileState, error) {
+	fi, err := c.fs.Stat(name)
+	if os.IsNotExist(err) {
+		return fileState{}, nil
+	} else if err != nil {
+		return fileState{}, err
+	}
+	return fileState{Size: fi.Size(), ModTime: fi.ModTime().UnixNano()}, nil
+}
+
+func (c *CachedTagSource) currentState() (refState, error) {
+	st := refState{Loose: map[string]fileState{}}
+	var err error
+	if st.Packed, err = c.statFile("packed-refs"); err != nil {
+		return st, err
+	}
+	var walk func(dir string) error
+	walk = func(dir string) error {
+		infos, err := c.fs.ReadDir(dir)
+		if os.IsNotExist(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		for _, fi := range infos {
+			name := path.Join(dir, fi.Name())
+			if fi.IsDir() {
+				if err := walk(name); err != nil {
+					return err
+				}
+				continue
+			}
+			if strings.HasSuffix(fi.Name(), ".lock") {
+				continue
+			}
+			st.Loose[name] = fileState{Size: fi.Size(), ModTime: fi.ModTime().UnixNano()}
+		}
+		return nil
+	}
+	return st, walk("refs/tags")
+}
+
+func (c *CachedTagSource) readCache() *tagCache {
+	f, err := c.fs.Open(TagCachePath)
+	if err != nil {
+		return nil
+	}
+	defer f.Close()
+	var tc tagCache
+	if err := json.NewDecoder(f).Decode(&tc); err != nil || tc.Format != cacheFormat || tc.Qualifiers != c.Parser.key() {
+		return nil
+	}
+	return &tc
+}
+
+// writeCache saves the entries under state. Failing to write only costs the
+// next run a rebuild, so errors are returned for callers that care.
+func (c *CachedTagSource) writeCache(state refState) error {
+	if err := c.fs.MkdirAll(path.Dir(TagCachePath), 0755); err != nil {
+		return err
+	}
+	tmp := TagCachePath + ".tmp"
+	f, err := c.fs.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(&tagCache{Format: cacheFormat, Qualifiers: c.Parser.key(), State: state, Entries: c.entries}); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return c.fs.Rename(tmp, TagCachePath)
+}
+
+func (c *CachedTagSource) index() {
+	c.byName = make(map[string]int, len(c.entries))
+	for i, e := range c.entries {
+		c.byName[e.Name] = i
+	}
+}
+
+// load fills the in-memory entries from the cache file, refreshing it when
+// the refs have changed. Callers must hold c.mu.
+func (c *CachedTagSource) load() error {
+	if c.loaded {
+		return nil
+	}
+	state, err := c.currentState()
+	if err != nil {
+		return err
+	}
+	cached := c.readCache()
+	if cached != nil && cached.State.equal(state) {
+		c.entries = cached.Entries
+		c.index()
+		c.loaded = true
+		return nil
+	}
+
+	previous := map[string]cacheEntry{}
+	if cached != nil {
+		for _, e := range cached.Entries {
+			previous[e.Name] = e
+		}
+	}
+	refs, err := c.GoGitTagSource.Tags()
+	if err != nil {
+		return err
+	}
+	entries := make([]cacheEntry, 0, len(refs))
+	for _, ref := range refs {
+		if e, ok := previous[ref.Name]; ok && e.Hash == ref.Hash {
+			entries = append(entries, e)
+			continue
+		}
+		commit, err := c.resolveHash(plumbing.NewHash(ref.Hash))
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", ref.Name, err)
+		}
+		e := cacheEntry{Name: ref.Name, Hash: ref.Hash, Commit: commit, Tag: c.Parser.ParseTag(ref.Name)}
+		if e.Tag != nil {
+			e.Tag.Hash = ref.Hash
+		}
+		entries = append(entries, e)
+	}
+	c.entries = entries
+	c.index()
+	c.loaded = true
+	_ = c.writeCache(state)
+	return nil
+}
+
+func (c *CachedTagSource) Tags() ([]TagRef, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	refs := make([]TagRef, 0, len(c.entries))
+	for _, e := range c.entries {
+		refs = append(refs, TagRef{Name: e.Name, Hash: e.Hash})
+	}
+	return refs, nil
+}
+
+// ParsedTags returns copies of the cached version tags.
+func (c *CachedTagSource) ParsedTags() ([]*Tag, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	tags := make([]*Tag, 0, len(c.entries))
+	for _, e := range c.entries {
+		if e.Tag != nil {
+			tags = append(tags, e.Tag.Clone())
+		}
+	}
+	return tags, nil
+}
+
+func (c *CachedTagSource) ResolveTag(name string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.load(); err != nil {
+		return "", err
+	}
+	i, ok := c.byName[name]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", name, ErrTagNotFound)
+	}
+	return c.entries[i].Commit, nil
+}
+
+// CreateTag creates the tag and adds it to the cache. When the tag already
+// exists the cache is reloaded on next use.
+func (c *CachedTagSource) CreateTag(name, target string, opts *CreateTagOptions) error {
+	if err := c.GoGitTagSource.CreateTag(name, target, opts); err != nil {
+		if errors.Is(err, ErrTagExists) {
+			// someone else created tags since the cache was loaded
+			c.mu.Lock()
+			c.loaded = false
+			c.mu.Unlock()
+		}
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.loaded {
+		return nil
+	}
+	ref, err := c.Repository.Tag(name)
+	if err != nil {
+		return err
+	}
+	e := cacheEntry{Name: name, Hash: ref.Hash().String(), Commit: target, Tag: c.Parser.ParseTag(name)}
+	if e.Tag != nil {
+		e.Tag.Hash = e.Hash
+	}
+	c.entries = append(c.entries, e)
+	c.index()
+	state, err := c.currentState()
+	if err != nil {
+		return nil
+	}
+	_ = c.writeCache(state)
+	return nil
+}
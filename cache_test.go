@@ -0,0 +1,133 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestCachedTagSource(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "f"), []byte("one"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("f"); err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+	c1, err := w.Commit("one", &git.CommitOptions{Author: sig})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CreateTag("v1.0.0-test.00", c1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CreateTag("v1.0.0-uat.01", c1, &git.CreateTagOptions{Message: "uat", Tagger: sig}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.CreateTag("notes", c1, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	open := func() *CachedTagSource {
+		t.Helper()
+		src, err := NewCachedTagSource(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return src
+	}
+
+	src := open()
+	highest, err := FindHighestVersionTag(src, "auto")
+	if err != nil || highest.String() != "v1.0.0-uat.01" {
+		t.Fatalf("highest got %s, %v", highest, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git", TagCachePath)); err != nil {
+		t.Fatalf("cache not written: %v", err)
+	}
+
+	// a fresh source reads the cache file back
+	tags, err := open().ParsedTags()
+	if err != nil || len(tags) != 2 {
+		t.Fatalf("ParsedTags got %v, %v", tags, err)
+	}
+	for _, tag := range tags {
+		if tag.String() == "v1.0.0-test.00" && tag.Test == nil {
+			t.Errorf("zero test counter lost in the cache")
+		}
+	}
+	h, err := open().ResolveTag("v1.0.0-uat.01")
+	if err != nil || h != c1.String() {
+		t.Errorf("ResolveTag got %s, %v", h, err)
+	}
+
+	// changes made outside the cache are picked up
+	if _, err := r.CreateTag("v1.0.1", c1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.DeleteTag("v1.0.0-uat.01"); err != nil {
+		t.Fatal(err)
+	}
+	src = open()
+	highest, err = FindHighestVersionTag(src, "auto")
+	if err != nil || highest.String() != "v1.0.1" {
+		t.Errorf("highest after change got %s, %v", highest, err)
+	}
+	if _, err := src.ResolveTag("v1.0.0-uat.01"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound for deleted tag, got %v", err)
+	}
+
+	// tags created through the source are added to the cache
+	if err := src.CreateTag("v1.0.2", c1.String(), nil); err != nil {
+		t.Fatal(err)
+	}
+	highest, err = FindHighestVersionTag(open(), "auto")
+	if err != nil || highest.String() != "v1.0.2" {
+		t.Errorf("highest after create got %s, %v", highest, err)
+	}
+
+	// tags are parsed again when the qualifiers change
+	if _, err := r.CreateTag("v1.0.2-hotfix.1", c1, nil); err != nil {
+		t.Fatal(err)
+	}
+	if highest, err = FindHighestVersionTag(open(), "auto"); err != nil || highest.String() != "v1.0.2" {
+		t.Errorf("highest without qualifiers got %s, %v", highest, err)
+	}
+	qualified := open()
+	qualified.Parser = testParser(t)
+	if highest, err = FindHighestVersionTag(qualified, "auto"); err != nil || highest.String() != "v1.0.2-hotfix.1" {
+		t.Errorf("highest with qualifiers got %s, %v", highest, err)
+	}
+
+	// lock files left by a concurrent git do not invalidate the cache
+	if err := os.WriteFile(filepath.Join(dir, ".git", "refs", "tags", "v1.0.3.lock"), []byte(c1.String()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := open().currentState()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := st.Loose["refs/tags/v1.0.3.lock"]; ok {
+		t.Errorf("currentState includes a lock file")
+	}
+}
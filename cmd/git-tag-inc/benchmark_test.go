@@ -9,9 +9,12 @@ package main
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/arran4/git-tag-inc"
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
@@ -70,12 +73,13 @@ func BenchmarkGetHash(b *testing.B) {
 		}
 	}
 
+	src := gittaginc.NewGoGitTagSource(r)
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
 		// We are benchmarking the sequence: Find tag, then GetHash
 		// This simulates the logic in main.go
-		highest, _ := FindHighestVersionTag(r)
-		_, _ = GetHash(r, highest)
+		highest, _ := gittaginc.FindHighestVersionTag(src, "auto")
+		_, _ = gittaginc.GetHash(src, highest)
 	}
 }
 
@@ -133,13 +137,85 @@ func BenchmarkGetHashOnly(b *testing.B) {
 		}
 	}
 
-	highest, err := FindHighestVersionTag(r)
+	src := gittaginc.NewGoGitTagSource(r)
+	highest, err := gittaginc.FindHighestVersionTag(src, "auto")
 	if err != nil {
 		b.Fatal(err)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, _ = GetHash(r, highest)
+		_, _ = gittaginc.GetHash(src, highest)
+	}
+}
+
+// largeTagRepo creates a repository with n version tags written straight to
+// packed-refs, as a repository with a long history of releases would have.
+func largeTagRepo(b *testing.B, n int) *git.Repository {
+	b.Helper()
+	dir := b.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		b.Fatal(err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		b.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "hello.txt"), []byte("hello"), 0644)
+	_, _ = w.Add("hello.txt")
+	commit, err := w.Commit("Initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		b.Fatal(err)
+	}
+	var packed strings.Builder
+	packed.WriteString("# pack-refs with: peeled fully-peeled sorted \n")
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&packed, "%s refs/tags/v%d.%d.%d-test.%02d\n", commit, i/10000, i/100%100, i%100, i%7+1)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".git", "packed-refs"), []byte(packed.String()), 0644); err != nil {
+		b.Fatal(err)
+	}
+	return r
+}
+
+var largeTagCounts = []int{1000, 20000, 80000}
+
+// BenchmarkFindHighestLarge times the lookups a bump makes, the repeat check
+// and the highest tag, with a fresh source each time as separate runs would.
+func BenchmarkFindHighestLarge(b *testing.B) {
+	run := func(b *testing.B, open func() (gittaginc.TagSource, error)) {
+		for i := 0; i < b.N; i++ {
+			src, err := open()
+			if err != nil {
+				b.Fatal(err)
+			}
+			similar, err := gittaginc.FindHighestSimilarVersionTag(src, "auto", "test")
+			if err != nil {
+				b.Fatal(err)
+			}
+			if _, err := gittaginc.GetHash(src, similar); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := gittaginc.FindHighestVersionTag(src, "auto"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	for _, n := range largeTagCounts {
+		r := largeTagRepo(b, n)
+		b.Run(fmt.Sprintf("uncached/%d", n), func(b *testing.B) {
+			run(b, func() (gittaginc.TagSource, error) {
+				return gittaginc.NewGoGitTagSource(r), nil
+			})
+		})
+		b.Run(fmt.Sprintf("cached/%d", n), func(b *testing.B) {
+			// the first run writes the cache file, later runs read it
+			run(b, func() (gittaginc.TagSource, error) {
+				return gittaginc.NewCachedTagSource(r)
+			})
+		})
 	}
 }
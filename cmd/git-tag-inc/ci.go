@@ -0,0 +1,120 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+// gitlabDotenvFile is written in the working directory under GitLab CI, to
+// be declared as an artifacts:reports:dotenv file.
+const gitlabDotenvFile = "git-tag-inc.env"
+
+// ciValue is a single named output.
+type ciValue struct {
+	Name  string
+	Value string
+}
+
+// ciValues are the outputs describing a run, in the order they are written.
+// previous is the version the run started from, "" when there was none.
+func ciValues(previous string, t *gittaginc.Tag, created bool) []ciValue {
+	c := newComponents(t)
+	return []ciValue{
+		{"version", t.String()},
+		{"previous_version", previous},
+		{"major", strconv.Itoa(c.Major)},
+		{"minor", strconv.Itoa(c.Minor)},
+		{"patch", strconv.Itoa(c.Patch)},
+		{"stage", c.Stage},
+		{"env", c.Env},
+		{"is_prerelease", strconv.FormatBool(gittaginc.IsPrerelease(t))},
+		{"tag_created", strconv.FormatBool(created)},
+	}
+}
+
+// previousVersion is the tag a bump started from, or "" when the repository
+// had none.
+func previousVersion(t *gittaginc.Tag) string {
+	if t == nil || t.Hash == "" {
+		return ""
+	}
+	return t.String()
+}
+
+// writeCIOutputs writes the outputs for the CI system detected from the
+// environment, GitHub Actions or GitLab CI, unless --ci=false, and to
+// --output-file. A failure ends the run, as later steps would otherwise read
+// stale values.
+func writeCIOutputs(previous string, t *gittaginc.Tag, target string, created bool) {
+	values := ciValues(previous, t, created)
+	var err error
+	if path := os.Getenv("GITHUB_OUTPUT"); path != "" && *ciOutputs {
+		err = appendFile(path, formatOutputs(values, false))
+	}
+	if path := os.Getenv("GITHUB_STEP_SUMMARY"); path != "" && *ciOutputs && err == nil {
+		err = appendFile(path, ciSummary(values, target))
+	}
+	if os.Getenv("GITLAB_CI") != "" && *ciOutputs && err == nil {
+		err = os.WriteFile(gitlabDotenvFile, []byte(formatOutputs(values, true)), 0o644)
+	}
+	if *outputFile != "" && err == nil {
+		err = os.WriteFile(*outputFile, []byte(formatOutputs(values, true)), 0o644)
+	}
+	if err != nil {
+		fail(ErrCodeCIOutput, "Failed to write CI outputs: %v", err)
+	}
+}
+
+// formatOutputs writes one name=value line per output, with upper case
+// names for dotenv files.
+func formatOutputs(values []ciValue, dotenv bool) string {
+	var b strings.Builder
+	for _, v := range values {
+		name := v.Name
+		if dotenv {
+			name = strings.ToUpper(name)
+		}
+		fmt.Fprintf(&b, "%s=%s\n", name, v.Value)
+	}
+	return b.String()
+}
+
+// ciSummary renders the outputs as a Markdown job summary.
+func ciSummary(values []ciValue, target string) string {
+	var b strings.Builder
+	b.WriteString("### git-tag-inc\n\n| Output | Value |\n| --- | --- |\n")
+	for _, v := range values {
+		value := "`" + v.Value + "`"
+		if v.Value == "" {
+			value = "-"
+		}
+		fmt.Fprintf(&b, "| %s | %s |\n", v.Name, value)
+	}
+	if target != "" {
+		fmt.Fprintf(&b, "| commit | `%s` |\n", target)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func appendFile(path, content string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(content); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
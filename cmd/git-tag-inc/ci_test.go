@@ -0,0 +1,71 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMain_CIOutputs(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.4.1", c1, false)
+	c2 := testCommit(t, r, dir, "two")
+
+	tmp := t.TempDir()
+	ghOutput := filepath.Join(tmp, "github_output")
+	ghSummary := filepath.Join(tmp, "github_summary")
+	outputFile := filepath.Join(tmp, "outputs.env")
+	cmd := exec.Command(exePath, "preview", "--output-file", outputFile, "patch", "rc")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GITHUB_OUTPUT="+ghOutput, "GITHUB_STEP_SUMMARY="+ghSummary, "GITLAB_CI=true")
+	if stdout, err := cmd.Output(); err != nil {
+		t.Fatalf("%v: %s", err, stdout)
+	}
+
+	read := func(path string) string {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	want := "version=v1.4.2-rc01\nprevious_version=v1.4.1\nmajor=1\nminor=4\npatch=2\nstage=rc\nenv=\nis_prerelease=true\ntag_created=false\n"
+	if got := read(ghOutput); got != want {
+		t.Errorf("GITHUB_OUTPUT got:\n%s\nwant:\n%s", got, want)
+	}
+	if got := read(outputFile); got != "VERSION=v1.4.2-rc01\nPREVIOUS_VERSION=v1.4.1\nMAJOR=1\nMINOR=4\nPATCH=2\nSTAGE=rc\nENV=\nIS_PRERELEASE=true\nTAG_CREATED=false\n" {
+		t.Errorf("--output-file got:\n%s", got)
+	}
+	if got := read(filepath.Join(dir, gitlabDotenvFile)); got != read(outputFile) {
+		t.Errorf("GitLab dotenv got:\n%s", got)
+	}
+	summary := read(ghSummary)
+	for _, s := range []string{"| version | `v1.4.2-rc01` |", "| tag_created | `false` |", "| env | - |", c2.String()} {
+		if !strings.Contains(summary, s) {
+			t.Errorf("summary missing %q:\n%s", s, summary)
+		}
+	}
+
+	cmd = exec.Command(exePath, "calc", "--ci=false", "--output-file", outputFile, "v2.0.0", "major")
+	cmd.Env = append(os.Environ(), "GITHUB_OUTPUT="+ghOutput)
+	if stdout, err := cmd.Output(); err != nil {
+		t.Fatalf("%v: %s", err, stdout)
+	}
+	if got := read(ghOutput); got != want {
+		t.Errorf("--ci=false still wrote GITHUB_OUTPUT:\n%s", got)
+	}
+	if got := read(outputFile); !strings.HasPrefix(got, "VERSION=v3.0.0\nPREVIOUS_VERSION=v2.0.0\n") {
+		t.Errorf("--output-file with --ci=false got:\n%s", got)
+	}
+}
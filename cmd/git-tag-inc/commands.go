@@ -0,0 +1,206 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"bytes"
+	"embed"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/template"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+// defaultSubcommand runs when the first argument is not a subcommand, so
+// `git-tag-inc patch` is `git-tag-inc bump patch`.
+const defaultSubcommand = "bump"
+
+// subcommand is one of the tool's subcommands.
+type subcommand struct {
+	// flags returns the subcommand's flag set, which help and completion
+	// also use.
+	flags func() *flag.FlagSet
+	run   func(args []string)
+	// summary is the line shown in the subcommand list.
+	summary string
+}
+
+// subcommands is filled in by init because the help and completion
+// subcommands refer back to it.
+var subcommands map[string]subcommand
+
+func init() {
+	subcommands = map[string]subcommand{
+		"bump": {
+			flags:   func() *flag.FlagSet { return newBumpFlags("bump") },
+			run:     runBump,
+			summary: "Tag HEAD with the next version (the default)",
+		},
+		"preview": {
+			flags:   func() *flag.FlagSet { return newBumpFlags("preview") },
+			run:     runPreview,
+			summary: "Print the version bump would create without tagging",
+		},
+		"calc": {
+			flags:   newCalcFlags,
+			run:     runCalc,
+			summary: "Increment a version given on the command line or stdin, offline",
+		},
+		"show": {
+			flags:   newShowFlags,
+			run:     runShow,
+			summary: "Show the parts, commit and date of a tag, the highest by default",
+		},
+		"version": {
+			flags:   func() *flag.FlagSet { return newFlagSet("version") },
+			run:     runVersion,
+			summary: "Print build information",
+		},
+		"list": {
+			flags:   func() *flag.FlagSet { fs, _ := newListFlags(); return fs },
+			run:     runList,
+			summary: "List version tags sorted by version",
+		},
+		"describe": {
+			flags:   func() *flag.FlagSet { fs, _ := newDescribeFlags(); return fs },
+			run:     runDescribe,
+			summary: "Print a pseudo version for an untagged commit",
+		},
+		"undo": {
+			flags:   func() *flag.FlagSet { fs, _ := newUndoFlags(); return fs },
+			run:     runUndo,
+			summary: "Delete the last tag this tool created",
+		},
+		"promote": {
+			flags:   func() *flag.FlagSet { fs, _ := newPromoteFlags(); return fs },
+			run:     runPromote,
+			summary: "Move a build from test to uat to release",
+		},
+		"lint": {
+			flags:   func() *flag.FlagSet { fs, _ := newLintFlags(); return fs },
+			run:     runLint,
+			summary: "Check tags for naming and ordering problems",
+		},
+		"completion": {
+			flags:   func() *flag.FlagSet { return newFlagSet("completion") },
+			run:     runCompletion,
+			summary: "Print a bash, zsh or fish completion script",
+		},
+		"help": {
+			flags:   func() *flag.FlagSet { return newFlagSet("help") },
+			run:     runHelp,
+			summary: "Show help for a subcommand",
+		},
+	}
+}
+
+// subcommandNames returns the subcommands in alphabetical order.
+func subcommandNames() []string {
+	names := make([]string, 0, len(subcommands))
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//go:embed help/*.txt
+var helpFiles embed.FS
+
+// newFlagSet returns a flag set whose -h output is help/<name>.txt.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		if err := writeHelp(fs.Output(), name, fs); err != nil {
+			panic(err)
+		}
+	}
+	return fs
+}
+
+// shareFlags adds the named global flags to fs. They set the same
+// variables, so they work before or after the subcommand.
+func shareFlags(fs *flag.FlagSet, names ...string) {
+	for _, name := range names {
+		f := flag.Lookup(name)
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+}
+
+// flagDefaults lists the flags of fs for a usage template.
+func flagDefaults(fs *flag.FlagSet) string {
+	var buf bytes.Buffer
+	fs.VisitAll(func(f *flag.Flag) {
+		fmt.Fprintf(&buf, "  -%s", f.Name)
+		name, usage := flag.UnquoteUsage(f)
+		if len(name) > 0 {
+			fmt.Fprintf(&buf, " %s", name)
+		}
+		if len(usage) > 24 {
+			fmt.Fprintf(&buf, "\n    \t")
+		} else {
+			fmt.Fprintf(&buf, "\t")
+		}
+		fmt.Fprintf(&buf, "%s", usage)
+		if f.DefValue != "" {
+			fmt.Fprintf(&buf, " (default %s)", f.DefValue)
+		}
+		fmt.Fprint(&buf, "\n")
+	})
+	return buf.String()
+}
+
+// usageData is what usage.txt and the help templates are rendered with.
+type usageData struct {
+	ProgramName     string
+	Flags           string
+	IsArraneousMode bool
+	Subcommands     string
+}
+
+func newUsageData(fs *flag.FlagSet) usageData {
+	var buf bytes.Buffer
+	for _, name := range subcommandNames() {
+		fmt.Fprintf(&buf, "  %-11s %s\n", name, subcommands[name].summary)
+	}
+	return usageData{
+		ProgramName:     os.Args[0],
+		Flags:           flagDefaults(fs),
+		IsArraneousMode: *mode == gittaginc.ModeArraneous,
+		Subcommands:     buf.String(),
+	}
+}
+
+// writeHelp renders help/<name>.txt for the subcommand's flag set.
+func writeHelp(w io.Writer, name string, fs *flag.FlagSet) error {
+	t, err := template.ParseFS(helpFiles, "help/"+name+".txt")
+	if err != nil {
+		return err
+	}
+	return t.Execute(w, newUsageData(fs))
+}
+
+func runHelp(args []string) {
+	fs := subcommands["help"].flags()
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		Usage()
+		return
+	}
+	sc, ok := subcommands[fs.Arg(0)]
+	if !ok {
+		fmt.Fprintf(out, "Unknown subcommand: %s\n", fs.Arg(0))
+		os.Exit(1)
+	}
+	if err := writeHelp(os.Stdout, fs.Arg(0), sc.flags()); err != nil {
+		panic(err)
+	}
+}
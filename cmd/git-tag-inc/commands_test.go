@@ -0,0 +1,115 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+func TestWriteHelp(t *testing.T) {
+	for _, name := range subcommandNames() {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := writeHelp(&buf, name, subcommands[name].flags()); err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(buf.String(), "Usage: "+os.Args[0]+" "+name) {
+				t.Errorf("unexpected help:\n%s", buf.String())
+			}
+		})
+	}
+}
+
+func TestMain_Subcommands(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0", c1, true)
+	testCommit(t, r, dir, "two")
+	setTestUser(t, r)
+
+	run := func(t *testing.T, stdin string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command(exePath, args...)
+		cmd.Dir = dir
+		cmd.Stdin = strings.NewReader(stdin)
+		stdout, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return strings.TrimSpace(string(stdout))
+	}
+
+	for _, tt := range []struct {
+		args  []string
+		stdin string
+		want  string
+	}{
+		{args: []string{"preview", "patch"}, want: "v1.0.1"},
+		{args: []string{"preview", "--mode", "legacy", "test"}, want: "v1.0.1-test01"},
+		{args: []string{"--print-version-only", "minor"}, want: "v1.1.0"},
+		{args: []string{"calc", "v1.2.3-test.04", "uat"}, want: "v1.2.3-uat.04"},
+		{args: []string{"calc", "-", "patch"}, stdin: "v0.1.1\n", want: "v0.1.2"},
+		{args: []string{"--base-version", "v2.0.0", "major"}, want: "v3.0.0"},
+		{args: []string{"version"}, want: "git-tag-inc version dev"},
+		{args: []string{"--version"}, want: "git-tag-inc version dev"},
+		{args: []string{"help", "show"}, want: "Usage: "},
+	} {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			if got := run(t, tt.stdin, tt.args...); !strings.HasPrefix(got, tt.want) {
+				t.Errorf("got %q want prefix %q", got, tt.want)
+			}
+		})
+	}
+
+	st, err := ShowTag(r, "")
+	if err != nil {
+		t.Fatalf("ShowTag: %v", err)
+	}
+	var buf bytes.Buffer
+	if err := writeShow(&buf, st); err != nil {
+		t.Fatalf("writeShow: %v", err)
+	}
+	shown := buf.String()
+	for _, s := range []string{"v1.0.0", c1.String(), "Tagger:", "test@example.com"} {
+		if !strings.Contains(shown, s) {
+			t.Errorf("show output missing %q:\n%s", s, shown)
+		}
+	}
+
+	run(t, "", "bump", "patch")
+	if _, err := r.Tag("v1.0.1"); err != nil {
+		t.Errorf("bump did not tag: %v", err)
+	}
+	testCommit(t, r, dir, "three")
+	run(t, "", "minor")
+	if _, err := r.Tag("v1.1.0"); err != nil {
+		t.Errorf("bare command did not tag: %v", err)
+	}
+	// next is a stage, not a subcommand
+	testCommit(t, r, dir, "four")
+	run(t, "", "next", "test")
+	if _, err := r.Tag("v1.1.1-next01-test01"); err != nil {
+		t.Errorf("next test did not tag: %v", err)
+	}
+}
+
+func TestSubcommandsAreNotCommands(t *testing.T) {
+	for _, mode := range []string{"auto", gittaginc.ModeLegacy, gittaginc.ModeArraneous} {
+		for _, c := range gittaginc.Commands(mode) {
+			if _, ok := subcommands[c]; ok {
+				t.Errorf("subcommand %s hides the %s command", c, c)
+			}
+		}
+	}
+}
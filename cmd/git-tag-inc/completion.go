@@ -0,0 +1,306 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+)
+
+// completeCommand is the hidden subcommand the completion scripts call with
+// the words typed so far, the last being the word under the cursor.
+const completeCommand = "__complete"
+
+// completionShells are the shells `completion` writes scripts for.
+var completionShells = []string{"bash", "zsh", "fish"}
+
+// tagFlags are the flags whose value is an existing tag.
+var tagFlags = map[string]bool{
+	"base-version": true,
+	"target":       true,
+	"min":          true,
+	"max":          true,
+	"rev":          true,
+}
+
+// flagValues are the fixed values of flags that take one.
+var flagValues = map[string][]string{
+	"mode":   {"auto", gittaginc.ModeSemver, gittaginc.ModeLegacy, gittaginc.ModeArraneous},
+	"output": {OutputText, OutputJSON, OutputDockerTags},
+	"env":    {"test", "uat", "none"},
+	"stage":  {"alpha", "beta", "rc", "next", "none"},
+}
+
+// completionWords returns the candidates for the last of words, which are
+// the arguments typed after the program name.
+func completionWords(words []string) []string {
+	if len(words) == 0 {
+		words = []string{""}
+	}
+	cur := words[len(words)-1]
+	before := words[:len(words)-1]
+
+	complMode := "auto"
+	repo := "."
+	config := ""
+	for i, w := range before {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+		if !hasValue && i+1 < len(before) {
+			value = before[i+1]
+		}
+		switch {
+		case !strings.HasPrefix(w, "-"):
+		case name == "mode":
+			complMode = value
+		case name == "repo" || name == "C":
+			repo = value
+		case name == "config":
+			config = value
+		}
+	}
+
+	// work out which flag set applies and whether a flag is waiting for its value
+	fs := flag.CommandLine
+	sub := ""
+	var pending *flag.Flag
+	for _, w := range before {
+		if pending != nil {
+			pending = nil
+			continue
+		}
+		if strings.HasPrefix(w, "-") && w != "-" {
+			name, _, hasValue := strings.Cut(strings.TrimLeft(w, "-"), "=")
+			if f := fs.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) {
+				pending = f
+			}
+			continue
+		}
+		if sub == "" {
+			if sc, ok := subcommands[w]; ok {
+				sub = w
+				fs = sc.flags()
+			}
+		}
+	}
+
+	prefix := ""
+	flagName := ""
+	if pending != nil {
+		flagName = pending.Name
+	} else if strings.HasPrefix(cur, "-") {
+		name, _, hasValue := strings.Cut(strings.TrimLeft(cur, "-"), "=")
+		if !hasValue {
+			return matching(flagNames(fs), cur)
+		}
+		flagName = name
+		prefix = cur[:strings.Index(cur, "=")+1]
+		cur = cur[len(prefix):]
+	}
+
+	var candidates []string
+	switch {
+	case flagName != "" && tagFlags[flagName]:
+		candidates = tagNames(repo)
+	case flagName != "":
+		candidates = flagValues[flagName]
+	case sub == "completion":
+		candidates = completionShells
+	case sub == "promote":
+		candidates = []string{"to", "uat", gittaginc.PromoteRelease}
+	case sub == "show":
+		candidates = tagNames(repo)
+	case sub == "help":
+		candidates = subcommandNames()
+	case sub == "bump" || sub == "preview" || sub == "calc":
+		loadQualifiers(repo, config)
+		candidates = parser.Commands(complMode)
+	case sub != "":
+	default:
+		loadQualifiers(repo, config)
+		if !hasCommand(before) {
+			candidates = subcommandNames()
+		}
+		candidates = append(candidates, parser.Commands(complMode)...)
+	}
+	var out []string
+	for _, c := range matching(candidates, cur) {
+		out = append(out, prefix+c)
+	}
+	return out
+}
+
+// hasCommand reports whether a bump command has already been typed, after
+// which subcommands are no longer offered.
+func hasCommand(words []string) bool {
+	for _, w := range words {
+		if !strings.HasPrefix(w, "-") && parser.CommandsToFlags([]string{w}, "auto").Valid {
+			return true
+		}
+	}
+	return false
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// flagNames lists a flag set as it is typed, -C and -i with one dash and the
+// rest with two.
+func flagNames(fs *flag.FlagSet) []string {
+	var names []string
+	fs.VisitAll(func(f *flag.Flag) {
+		if len(f.Name) == 1 {
+			names = append(names, "-"+f.Name)
+		} else {
+			names = append(names, "--"+f.Name)
+		}
+	})
+	return names
+}
+
+func matching(candidates []string, prefix string) []string {
+	var out []string
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// loadQualifiers sets parser to the qualifiers configured in config, or in
+// the repository at path without one, so they are offered as commands. Any
+// problem is ignored.
+func loadQualifiers(path, config string) {
+	if config == "" {
+		r, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+		if err != nil {
+			return
+		}
+		wt, err := r.Worktree()
+		if err != nil {
+			return
+		}
+		config = filepath.Join(wt.Filesystem.Root(), gittaginc.ConfigFile)
+	}
+	cfg, err := gittaginc.LoadConfig(config)
+	if err != nil {
+		return
+	}
+	if p, err := gittaginc.NewParser(cfg.Qualifiers); err == nil {
+		parser = p
+	}
+}
+
+// tagNames lists the tags of the repository at path, or nothing when there
+// is no repository.
+func tagNames(path string) []string {
+	r, err := git.PlainOpenWithOptions(path, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		return nil
+	}
+	refs, err := gittaginc.NewGoGitTagSource(r).Tags()
+	if err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		names = append(names, ref.Name)
+	}
+	return names
+}
+
+func runComplete(args []string) {
+	for _, c := range completionWords(args) {
+		fmt.Println(c)
+	}
+}
+
+var completionScripts = map[string]string{
+	"bash": `# bash completion for {{.Program}}
+# eval "$({{.Program}} completion bash)"
+_{{.Func}}() {
+	local line="${COMP_LINE:0:COMP_POINT}"
+	local -a words
+	read -r -a words <<< "$line"
+	[[ "$line" == *" " ]] && words+=("")
+	local cur="${words[${#words[@]}-1]}"
+	local IFS=$'\n'
+	COMPREPLY=($({{.Program}} {{.Complete}} "${words[@]:1}" 2>/dev/null))
+	# bash splits --flag=value at the "=", so only the value is replaced
+	if [[ "$cur" == *=* && "$COMP_WORDBREAKS" == *=* ]]; then
+		COMPREPLY=("${COMPREPLY[@]#*=}")
+	fi
+}
+complete -o default -F _{{.Func}} {{.Program}}
+`,
+	"zsh": `#compdef {{.Program}}
+# zsh completion for {{.Program}}
+# source <({{.Program}} completion zsh)
+_{{.Func}}() {
+	local -a candidates
+	candidates=("${(@f)$({{.Program}} {{.Complete}} "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+	if (( ${#candidates} )) && [[ -n "${candidates[1]}" ]]; then
+		compadd -Q -- "${candidates[@]}"
+	else
+		_files
+	fi
+}
+compdef _{{.Func}} {{.Program}}
+`,
+	"fish": `# fish completion for {{.Program}}
+# {{.Program}} completion fish | source
+function __{{.Func}}_complete
+	set -l words (commandline -opc)
+	{{.Program}} {{.Complete}} $words[2..-1] (commandline -ct) 2>/dev/null
+end
+complete -c {{.Program}} -f -a '(__{{.Func}}_complete)'
+`,
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// writeCompletion writes the completion script for shell.
+func writeCompletion(w io.Writer, shell, program string) error {
+	script, ok := completionScripts[shell]
+	if !ok {
+		return fmt.Errorf("unknown shell %q, expected one of %s", shell, strings.Join(completionShells, ", "))
+	}
+	t := template.Must(template.New(shell).Parse(script))
+	return t.Execute(w, struct {
+		Program  string
+		Func     string
+		Complete string
+	}{
+		Program:  program,
+		Func:     nonIdentifier.ReplaceAllString(program, "_"),
+		Complete: completeCommand,
+	})
+}
+
+func runCompletion(args []string) {
+	fs := subcommands["completion"].flags()
+	_ = fs.Parse(args)
+	if fs.NArg() != 1 {
+		fmt.Fprintf(out, "Usage: completion <%s>\n", strings.Join(completionShells, "|"))
+		os.Exit(1)
+	}
+	if err := writeCompletion(os.Stdout, fs.Arg(0), filepath.Base(os.Args[0])); err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,73 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+func TestCompletionWords(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0", c1, false)
+	testTag(t, r, "v1.1.0-test.01", c1, true)
+	config := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(config, []byte(`{"qualifiers": [{"name": "hotfix", "rank": 1}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	defer func(p *gittaginc.Parser) { parser = p }(parser)
+
+	for _, tt := range []struct {
+		words []string
+		want  string
+	}{
+		{[]string{"p"}, "preview promote patch"},
+		{[]string{"--mode", "arraneous", "p"}, "preview promote"},
+		{[]string{"--mode=arraneous", "patch", "r"}, "release rc"},
+		{[]string{"patch", "t"}, "test"},
+		{[]string{"--mo"}, "--mode"},
+		{[]string{"--mode", ""}, "auto semver legacy arraneous"},
+		{[]string{"--output=j"}, "--output=json"},
+		{[]string{"--dry", "u"}, "undo uat"},
+		{[]string{"-C", dir, "--base-version", "v1.1"}, "v1.1.0-test.01"},
+		{[]string{"--repo=" + dir, "promote", "--target="}, "--target=v1.0.0 --target=v1.1.0-test.01"},
+		{[]string{"promote", ""}, "to uat release"},
+		{[]string{"list", "--en"}, "--env"},
+		{[]string{"list", "--env", ""}, "test uat none"},
+		{[]string{"completion", ""}, "bash zsh fish"},
+		{[]string{"-C", ""}, ""},
+		{[]string{"--config", config, "patch", "h"}, "hotfix"},
+		{[]string{"--config=" + config, "h"}, "help hotfix"},
+	} {
+		t.Run(strings.Join(tt.words, " "), func(t *testing.T) {
+			if got := strings.Join(completionWords(tt.words), " "); got != tt.want {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteCompletion(t *testing.T) {
+	for _, shell := range completionShells {
+		var buf bytes.Buffer
+		if err := writeCompletion(&buf, shell, "git-tag-inc"); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), "git-tag-inc "+completeCommand) || !strings.Contains(buf.String(), "git_tag_inc") {
+			t.Errorf("unexpected %s script:\n%s", shell, buf.String())
+		}
+	}
+	if err := writeCompletion(&bytes.Buffer{}, "tcsh", "git-tag-inc"); err == nil {
+		t.Errorf("expected an error for an unknown shell")
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+type describeResult struct {
+	Version  string `json:"version"`
+	Base     string `json:"base"`
+	Distance int    `json:"distance"`
+	Commit   string `json:"commit"`
+}
+
+// describeRevision describes the commit h against the nearest version tag,
+// abbreviating its hash to abbrev characters.
+func describeRevision(r *git.Repository, h plumbing.Hash, abbrev int) (*describeResult, error) {
+	src := newTagSource(r)
+	d, err := gittaginc.DescribeCommit(src, src, h.String(), *mode)
+	if err != nil {
+		return nil, err
+	}
+	return &describeResult{
+		Version:  d.Version(abbrev),
+		Base:     d.Base.String(),
+		Distance: d.Distance,
+		Commit:   d.Commit,
+	}, nil
+}
+
+type describeFlags struct {
+	rev    *string
+	abbrev *int
+}
+
+// newDescribeFlags defines the flags of the describe subcommand.
+func newDescribeFlags() (*flag.FlagSet, *describeFlags) {
+	fs := newFlagSet("describe")
+	f := &describeFlags{
+		rev:    fs.String("rev", "HEAD", "Revision to describe"),
+		abbrev: fs.Int("abbrev", 7, "Length of the abbreviated commit hash"),
+	}
+	fs.StringVar(output, "output", *output, "Output format: text or json")
+	return fs, f
+}
+
+func runDescribe(args []string) {
+	fs, f := newDescribeFlags()
+	_ = fs.Parse(args)
+
+	r := openRepository()
+	h, err := r.ResolveRevision(plumbing.Revision(*f.rev))
+	if err != nil {
+		fmt.Fprintf(out, "Failed to resolve %s: %v\n", *f.rev, err)
+		os.Exit(1)
+	}
+	d, err := describeRevision(r, *h, *f.abbrev)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to describe %s: %v\n", *f.rev, err)
+		os.Exit(1)
+	}
+	if *output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(d); err != nil {
+			fmt.Fprintf(out, "Failed to write JSON output: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Println(d.Version)
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import "testing"
+
+func TestDescribeRevision(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	untagged, err := describeRevision(r, c1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "v0.0.0-dev.1+g" + c1.String()[:7]; untagged.Version != want {
+		t.Errorf("untagged got %s want %s", untagged.Version, want)
+	}
+
+	testTag(t, r, "v1.2.4-test.3", c1, true)
+	testTag(t, r, "v1.2.3", c1, false)
+	exact, err := describeRevision(r, c1, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exact.Version != "v1.2.4-test.3" || exact.Distance != 0 {
+		t.Errorf("exact got %#v", exact)
+	}
+
+	testCommit(t, r, dir, "two")
+	c3 := testCommit(t, r, dir, "three")
+	ahead, err := describeRevision(r, c3, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "v1.2.4-test.3.dev.2+g" + c3.String()[:7]; ahead.Version != want {
+		t.Errorf("ahead got %s want %s", ahead.Version, want)
+	}
+}
@@ -0,0 +1,275 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"golang.org/x/term"
+)
+
+// errCancelled is returned when the user quits the picker or declines to
+// create the tag.
+var errCancelled = errors.New("cancelled")
+
+// maxPickerCommits limits how many commits since the highest tag are shown.
+const maxPickerCommits = 10
+
+// candidate is one next version offered by the picker.
+type candidate struct {
+	Commands []string
+	Next     *gittaginc.Tag
+}
+
+// picker holds what the interactive picker shows.
+type picker struct {
+	Highest       *gittaginc.Tag
+	HighestCommit string
+	Head          string
+	Commits       []*object.Commit
+	More          bool
+	Candidates    []candidate
+}
+
+// pickerCommands are the single commands offered, in menu order.
+func pickerCommands(mode string) []string {
+	patch := "patch"
+	if mode == gittaginc.ModeArraneous {
+		patch = "release"
+	}
+	cmds := []string{"major", "minor", patch, "alpha", "beta", "rc", "next"}
+	for _, q := range parser.Qualifiers() {
+		cmds = append(cmds, q.Name)
+	}
+	return append(cmds, "test", "uat")
+}
+
+// newPicker finds the highest tag, HEAD and the commits between them, and
+// previews each command with Tag.IncrementWithOptions and opts, which should
+// carry the same options bump applies. Commands that cannot be applied,
+// including stage changes transitions do not allow, are left out.
+func newPicker(r *git.Repository, src gittaginc.TagSource, mode string, opts gittaginc.IncrementOptions) (*picker, error) {
+	highest, err := gittaginc.FindHighestVersionTag(src, mode)
+	if err != nil {
+		return nil, fmt.Errorf("finding the highest tag: %w", err)
+	}
+	p := &picker{Highest: highest}
+	if p.HighestCommit, err = gittaginc.GetHash(src, highest); err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", highest, err)
+	}
+	if p.Head, err = src.Head(); err != nil {
+		return nil, fmt.Errorf("reading HEAD: %w", err)
+	}
+
+	iter, err := r.Log(&git.LogOptions{From: plumbing.NewHash(p.Head)})
+	if err != nil {
+		return nil, err
+	}
+	err = iter.ForEach(func(c *object.Commit) error {
+		if c.Hash.String() == p.HighestCommit {
+			return storer.ErrStop
+		}
+		if len(p.Commits) == maxPickerCommits {
+			p.More = true
+			return storer.ErrStop
+		}
+		p.Commits = append(p.Commits, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Qualifiers = parser.Qualifiers()
+	for _, cmd := range pickerCommands(mode) {
+		next := highest.Clone()
+		if err := next.IncrementWithOptions(parser.CommandsToFlags([]string{cmd}, mode), opts); err != nil {
+			continue
+		}
+		p.Candidates = append(p.Candidates, candidate{Commands: []string{cmd}, Next: next})
+	}
+	return p, nil
+}
+
+// typeNumber appends the digits of key to the number typed so far and returns
+// the new number with the index of the candidate it picks. When no candidate
+// has that number key is taken on its own, and failing that the index is -1.
+func typeNumber(typed, key string, count int) (string, int) {
+	for _, s := range []string{typed + key, key} {
+		if n, err := strconv.Atoi(s); err == nil && n >= 1 && n <= count {
+			return s, n - 1
+		}
+	}
+	return "", -1
+}
+
+func shortHash(h string) string {
+	if len(h) > 7 {
+		return h[:7]
+	}
+	return h
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return line
+}
+
+// writeSummary prints the highest tag, HEAD and the commits since the tag.
+func (p *picker) writeSummary(w io.Writer, nl string) {
+	if p.HighestCommit == "" {
+		fmt.Fprintf(w, "Current: none%s", nl)
+	} else {
+		fmt.Fprintf(w, "Current: %s (%s)%s", p.Highest, shortHash(p.HighestCommit), nl)
+	}
+	fmt.Fprintf(w, "HEAD:    %s%s", shortHash(p.Head), nl)
+	if len(p.Commits) == 0 {
+		fmt.Fprintf(w, "No commits since %s%s", p.Highest, nl)
+		return
+	}
+	fmt.Fprintf(w, "Commits since %s:%s", p.Highest, nl)
+	for _, c := range p.Commits {
+		fmt.Fprintf(w, "  %s %s%s", shortHash(c.Hash.String()), firstLine(c.Message), nl)
+	}
+	if p.More {
+		fmt.Fprintf(w, "  ...%s", nl)
+	}
+}
+
+func (p *picker) writeChoice(w io.Writer, i int, selected bool, nl string) {
+	c := p.Candidates[i]
+	marker := "  "
+	if selected {
+		marker = "> "
+	}
+	fmt.Fprintf(w, "%s%d) %-6s %s%s", marker, i+1, strings.Join(c.Commands, " "), c.Next, nl)
+}
+
+// pickCommands asks which version to create and returns its commands, or
+// errCancelled. A terminal gets a menu driven by the arrow keys; any other
+// input gets numbered prompts read a line at a time.
+func pickCommands(p *picker, in io.Reader, w io.Writer) ([]string, error) {
+	if len(p.Candidates) == 0 {
+		return nil, fmt.Errorf("no version can follow %s", p.Highest)
+	}
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return pickCommandsTerminal(p, f, w)
+	}
+	return pickCommandsLines(p, bufio.NewReader(in), w)
+}
+
+func pickCommandsLines(p *picker, in *bufio.Reader, w io.Writer) ([]string, error) {
+	p.writeSummary(w, "\n")
+	fmt.Fprintln(w)
+	for i := range p.Candidates {
+		p.writeChoice(w, i, false, "\n")
+	}
+	var chosen candidate
+	for {
+		fmt.Fprintf(w, "Choose a version [1-%d, q to quit]: ", len(p.Candidates))
+		line, err := in.ReadString('\n')
+		answer := strings.TrimSpace(line)
+		if answer == "q" || (answer == "" && err != nil) {
+			return nil, errCancelled
+		}
+		n, convErr := strconv.Atoi(answer)
+		if convErr == nil && n >= 1 && n <= len(p.Candidates) {
+			chosen = p.Candidates[n-1]
+			break
+		}
+		fmt.Fprintf(w, "%q is not a choice\n", answer)
+		if err != nil {
+			return nil, errCancelled
+		}
+	}
+	fmt.Fprintf(w, "Create %s on %s? [y/N]: ", chosen.Next, shortHash(p.Head))
+	line, _ := in.ReadString('\n')
+	if answer := strings.ToLower(strings.TrimSpace(line)); answer != "y" && answer != "yes" {
+		return nil, errCancelled
+	}
+	return chosen.Commands, nil
+}
+
+func pickCommandsTerminal(p *picker, f *os.File, w io.Writer) ([]string, error) {
+	state, err := term.MakeRaw(int(f.Fd()))
+	if err != nil {
+		return pickCommandsLines(p, bufio.NewReader(f), w)
+	}
+	defer func() { _ = term.Restore(int(f.Fd()), state) }()
+
+	// raw mode needs explicit carriage returns
+	const nl = "\r\n"
+	p.writeSummary(w, nl)
+	fmt.Fprintf(w, "%sUse the arrow keys or a number, enter to choose, q to quit%s", nl, nl)
+	selected := 0
+	typed := ""
+	draw := func(redraw bool) {
+		if redraw {
+			fmt.Fprintf(w, "\x1b[%dA\x1b[J", len(p.Candidates))
+		}
+		for i := range p.Candidates {
+			p.writeChoice(w, i, i == selected, nl)
+		}
+	}
+	draw(false)
+
+	buf := make([]byte, 8)
+	for chosen := false; !chosen; {
+		n, err := f.Read(buf)
+		if err != nil {
+			return nil, errCancelled
+		}
+		key := string(buf[:n])
+		if strings.Trim(key, "0123456789") == "" {
+			var i int
+			if typed, i = typeNumber(typed, key, len(p.Candidates)); i < 0 {
+				continue
+			}
+			selected = i
+			draw(true)
+			continue
+		}
+		typed = ""
+		switch {
+		case key == "q" || key == "\x1b" || key == "\x03":
+			return nil, errCancelled
+		case key == "\r" || key == "\n":
+			chosen = true
+		case key == "\x1b[A" || key == "k":
+			if selected > 0 {
+				selected--
+			}
+		case key == "\x1b[B" || key == "j":
+			if selected < len(p.Candidates)-1 {
+				selected++
+			}
+		default:
+			continue
+		}
+		draw(true)
+	}
+
+	c := p.Candidates[selected]
+	fmt.Fprintf(w, "Create %s on %s? [y/N] ", c.Next, shortHash(p.Head))
+	n, err := f.Read(buf)
+	fmt.Fprint(w, nl)
+	if err != nil || n == 0 || (buf[0] != 'y' && buf[0] != 'Y') {
+		return nil, errCancelled
+	}
+	return c.Commands, nil
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+func TestPickCommands(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.2.3", c1, true)
+	testCommit(t, r, dir, "two")
+	testCommit(t, r, dir, "three")
+
+	p, err := newPicker(r, gittaginc.NewGoGitTagSource(r), "auto", gittaginc.IncrementOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Highest.String() != "v1.2.3" || p.HighestCommit != c1.String() || len(p.Commits) != 2 || p.More {
+		t.Fatalf("unexpected picker %+v", p)
+	}
+
+	for _, tt := range []struct {
+		name  string
+		input string
+		want  string
+		err   error
+	}{
+		{name: "minor", input: "2\ny\n", want: "minor"},
+		{name: "retry after bad choice", input: "x\n99\n8\nyes\n", want: "test"},
+		{name: "declined", input: "1\nn\n", err: errCancelled},
+		{name: "quit", input: "q\n", err: errCancelled},
+		{name: "eof", input: "", err: errCancelled},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var w bytes.Buffer
+			cmds, err := pickCommands(p, strings.NewReader(tt.input), &w)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("got error %v want %v", err, tt.err)
+			}
+			if got := strings.Join(cmds, " "); got != tt.want {
+				t.Errorf("got %q want %q", got, tt.want)
+			}
+			for _, s := range []string{"Current: v1.2.3", " three", "2) minor  v1.3.0", "8) test   v1.2.4-test01"} {
+				if !strings.Contains(w.String(), s) {
+					t.Errorf("output missing %q:\n%s", s, w.String())
+				}
+			}
+		})
+	}
+}
+
+func TestTypeNumber(t *testing.T) {
+	for _, tt := range []struct {
+		typed, key string
+		count      int
+		want       string
+		index      int
+	}{
+		{"", "3", 11, "3", 2},
+		{"1", "1", 11, "11", 10},
+		{"1", "2", 11, "2", 1},
+		{"", "12", 11, "", -1},
+		{"", "0", 11, "", -1},
+	} {
+		typed, i := typeNumber(tt.typed, tt.key, tt.count)
+		if typed != tt.want || i != tt.index {
+			t.Errorf("typeNumber(%q, %q, %d) = %q, %d want %q, %d", tt.typed, tt.key, tt.count, typed, i, tt.want, tt.index)
+		}
+	}
+}
+
+func TestPickerPreviewOptions(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.2.3-rc.01", c1, true)
+
+	has := func(opts gittaginc.IncrementOptions, cmd string) bool {
+		t.Helper()
+		p, err := newPicker(r, gittaginc.NewGoGitTagSource(r), "auto", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range p.Candidates {
+			if c.Commands[0] == cmd {
+				return true
+			}
+		}
+		return false
+	}
+	if has(gittaginc.IncrementOptions{}, "alpha") {
+		t.Errorf("alpha offered after rc without --force")
+	}
+	if !has(gittaginc.IncrementOptions{Force: true}, "alpha") {
+		t.Errorf("alpha not offered after rc with --force")
+	}
+}
+
+func TestMain_Interactive(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0", c1, false)
+	testCommit(t, r, dir, "two")
+	setTestUser(t, r)
+
+	cmd := exec.Command(exePath, "-i")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader("3\ny\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("unexpected error: %v\n%s", err, output)
+	}
+	if !strings.Contains(string(output), "Creating v1.0.1") {
+		t.Errorf("unexpected output %s", output)
+	}
+	if _, err := r.Tag("v1.0.1"); err != nil {
+		t.Errorf("tag not created: %v", err)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"os"
+	"path"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// journalPath is relative to the repository's .git directory.
+var journalPath = path.Join("git-tag-inc", "journal")
+
+// journalEntry records a tag created by the tool so it can be undone later.
+type journalEntry struct {
+	Tag      string    `json:"tag"`
+	Commit   string    `json:"commit"`
+	Previous string    `json:"previous,omitempty"`
+	Created  time.Time `json:"created"`
+}
+
+func journalFS(r *git.Repository) (billy.Filesystem, error) {
+	s, ok := r.Storer.(*filesystem.Storage)
+	if !ok {
+		return nil, errors.New("the tag journal requires an on-disk repository")
+	}
+	return s.Filesystem(), nil
+}
+
+// appendJournal adds an entry to the end of the journal.
+func appendJournal(r *git.Repository, e journalEntry) error {
+	fs, err := journalFS(r)
+	if err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(path.Dir(journalPath), 0755); err != nil {
+		return err
+	}
+	f, err := fs.OpenFile(journalPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(e); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readJournal returns the journal entries oldest first. A missing journal is
+// treated as empty.
+func readJournal(r *git.Repository) ([]journalEntry, error) {
+	fs, err := journalFS(r)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fs.Open(journalPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var entries []journalEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var e journalEntry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// writeJournal replaces the journal with entries.
+func writeJournal(r *git.Repository, entries []journalEntry) error {
+	fs, err := journalFS(r)
+	if err != nil {
+		return err
+	}
+	f, err := fs.Create(journalPath)
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			_ = f.Close()
+			return err
+		}
+	}
+	return f.Close()
+}
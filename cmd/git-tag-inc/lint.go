@@ -0,0 +1,391 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// lintIssue is a single problem found by lint.
+type lintIssue struct {
+	Severity string   `json:"severity"`
+	Check    string   `json:"check"`
+	Tags     []string `json:"tags"`
+	Message  string   `json:"message"`
+}
+
+var nearMissRe = regexp.MustCompile(`(?i)^v?\d+\.\d+`)
+
+// LintTags scans every tag in the repository and reports problems with how
+// they would be interpreted.
+func LintTags(r *git.Repository) ([]lintIssue, error) {
+	entries, err := ListTags(r, listFilter{Invalid: true})
+	if err != nil {
+		return nil, err
+	}
+	var valid []*listEntry
+	var issues []lintIssue
+	for _, e := range entries {
+		if e.Valid {
+			valid = append(valid, e)
+			continue
+		}
+		if issue := lintNearMiss(e.Name); issue != nil {
+			issues = append(issues, *issue)
+		}
+	}
+	issues = append(issues, lintModes(valid)...)
+	issues = append(issues, lintPadding(valid)...)
+	issues = append(issues, lintSharedCommits(valid)...)
+	issues = append(issues, lintGaps(valid)...)
+	ancestry, err := lintAncestry(r, valid)
+	if err != nil {
+		return nil, err
+	}
+	return append(issues, ancestry...), nil
+}
+
+func lintNearMiss(name string) *lintIssue {
+	if !nearMissRe.MatchString(name) {
+		return nil
+	}
+	msg := fmt.Sprintf("%s looks like a version but is not recognised", name)
+	candidate := strings.ToLower(name)
+	if !strings.HasPrefix(candidate, "v") {
+		candidate = "v" + candidate
+	}
+	if t := parser.ParseTag(candidate); t != nil {
+		msg += fmt.Sprintf("; did you mean %s?", candidate)
+	}
+	return &lintIssue{Severity: SeverityWarning, Check: "near-miss", Tags: []string{name}, Message: msg}
+}
+
+// hasSuffix reports whether a tag carries anything after vX.Y.Z, which is
+// what distinguishes semver from legacy naming.
+func hasSuffix(t *gittaginc.Tag) bool {
+	return t.Stage != nil || t.Test != nil || t.Uat != nil || t.Release != nil
+}
+
+func lintModes(entries []*listEntry) []lintIssue {
+	byMode := map[string][]string{}
+	for _, e := range entries {
+		if hasSuffix(e.Tag) {
+			byMode[e.Tag.Mode] = append(byMode[e.Tag.Mode], e.Name)
+		}
+	}
+	if len(byMode[gittaginc.ModeSemver]) == 0 || len(byMode[gittaginc.ModeLegacy]) == 0 {
+		return nil
+	}
+	return []lintIssue{{
+		Severity: SeverityWarning,
+		Check:    "mixed-modes",
+		Tags:     []string{byMode[gittaginc.ModeSemver][0], byMode[gittaginc.ModeLegacy][0]},
+		Message: fmt.Sprintf("%d semver and %d legacy tags; e.g. %s and %s",
+			len(byMode[gittaginc.ModeSemver]), len(byMode[gittaginc.ModeLegacy]),
+			byMode[gittaginc.ModeSemver][0], byMode[gittaginc.ModeLegacy][0]),
+	}}
+}
+
+func lintPadding(entries []*listEntry) []lintIssue {
+	type counter struct {
+		name  string
+		value *int
+		pad   int
+	}
+	type unpaddedTag struct {
+		tag   string
+		value int
+	}
+	// widths holds, per counter, the first tag seen with each zero-padded
+	// width, and unpadded the tags whose counter has no leading zeros.
+	widths := map[string]map[int]string{}
+	unpadded := map[string][]unpaddedTag{}
+	var names []string
+	for _, e := range entries {
+		var counters []counter
+		if e.Tag.Stage != nil {
+			counters = append(counters, counter{e.Tag.StageName, e.Tag.Stage, e.Tag.StagePad})
+		}
+		if e.Tag.Qualifier != nil {
+			counters = append(counters, counter{e.Tag.QualifierName, e.Tag.Qualifier, e.Tag.QualifierPad})
+		}
+		if e.Tag.Uat != nil {
+			counters = append(counters, counter{"uat", e.Tag.Uat, e.Tag.Pad})
+		} else if e.Tag.Test != nil {
+			counters = append(counters, counter{"test", e.Tag.Test, e.Tag.Pad})
+		}
+		for _, c := range counters {
+			if widths[c.name] == nil {
+				widths[c.name] = map[int]string{}
+				names = append(names, c.name)
+			}
+			if c.pad > len(strconv.Itoa(*c.value)) {
+				if _, ok := widths[c.name][c.pad]; !ok {
+					widths[c.name][c.pad] = e.Name
+				}
+			} else {
+				unpadded[c.name] = append(unpadded[c.name], unpaddedTag{e.Name, *c.value})
+			}
+		}
+	}
+	var issues []lintIssue
+	for _, name := range names {
+		var padded []int
+		for w := range widths[name] {
+			padded = append(padded, w)
+		}
+		sort.Ints(padded)
+		if len(padded) > 1 {
+			issues = append(issues, lintIssue{
+				Severity: SeverityWarning,
+				Check:    "padding",
+				Tags:     []string{widths[name][padded[0]], widths[name][padded[1]]},
+				Message:  fmt.Sprintf("%s counters are zero padded to different widths: %s and %s", name, widths[name][padded[0]], widths[name][padded[1]]),
+			})
+			continue
+		}
+		if len(padded) == 0 {
+			continue
+		}
+		for _, u := range unpadded[name] {
+			if len(strconv.Itoa(u.value)) < padded[0] {
+				issues = append(issues, lintIssue{
+					Severity: SeverityWarning,
+					Check:    "padding",
+					Tags:     []string{u.tag, widths[name][padded[0]]},
+					Message:  fmt.Sprintf("%s counter of %s is not zero padded like %s", name, u.tag, widths[name][padded[0]]),
+				})
+				break
+			}
+		}
+	}
+	return issues
+}
+
+// baseKey identifies a version without its environment counter, so the
+// test, uat and release tags of one build share it.
+func baseKey(t *gittaginc.Tag) string {
+	b := t.Clone()
+	b.Test = nil
+	b.Uat = nil
+	b.Release = nil
+	b.Pad = 0
+	b.Mode = gittaginc.ModeSemver
+	return b.String()
+}
+
+func lintSharedCommits(entries []*listEntry) []lintIssue {
+	byCommit := map[string][]*listEntry{}
+	var commits []string
+	for _, e := range entries {
+		if byCommit[e.Commit] == nil {
+			commits = append(commits, e.Commit)
+		}
+		byCommit[e.Commit] = append(byCommit[e.Commit], e)
+	}
+	var issues []lintIssue
+	for _, c := range commits {
+		tags := byCommit[c]
+		for i := 1; i < len(tags); i++ {
+			a, b := tags[i-1], tags[i]
+			aEnv, _ := gittaginc.EnvInfo(a.Tag)
+			bEnv, _ := gittaginc.EnvInfo(b.Tag)
+			sameVersion := a.Tag.Major == b.Tag.Major && a.Tag.Minor == b.Tag.Minor && a.Tag.Patch == b.Tag.Patch
+			if sameVersion && aEnv != bEnv {
+				// promoting a build through environments reuses its commit
+				continue
+			}
+			issues = append(issues, lintIssue{
+				Severity: SeverityWarning,
+				Check:    "shared-commit",
+				Tags:     []string{a.Name, b.Name},
+				Message:  fmt.Sprintf("%s and %s are both on commit %s", a.Name, b.Name, c),
+			})
+		}
+	}
+	return issues
+}
+
+// lintGaps reports missing numbers in the environment counters of each
+// version. Test and uat share one sequence because promotion keeps the
+// counter, so v1.0.0-test.01, v1.0.0-test.02 and v1.0.0-uat.03 have no gap.
+func lintGaps(entries []*listEntry) []lintIssue {
+	seen := map[string]map[int]string{}
+	var keys []string
+	for _, e := range entries {
+		_, v := gittaginc.EnvInfo(e.Tag)
+		if v == nil {
+			continue
+		}
+		key := baseKey(e.Tag)
+		if seen[key] == nil {
+			seen[key] = map[int]string{}
+			keys = append(keys, key)
+		}
+		if _, ok := seen[key][*v]; !ok {
+			seen[key][*v] = e.Name
+		}
+	}
+	var issues []lintIssue
+	for _, key := range keys {
+		var values []int
+		for v := range seen[key] {
+			values = append(values, v)
+		}
+		sort.Ints(values)
+		for i := 1; i < len(values); i++ {
+			if values[i]-values[i-1] > 1 {
+				from, to := seen[key][values[i-1]], seen[key][values[i]]
+				issues = append(issues, lintIssue{
+					Severity: SeverityWarning,
+					Check:    "gap",
+					Tags:     []string{from, to},
+					Message:  fmt.Sprintf("environment counter jumps from %s to %s", from, to),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+// lintAncestry reports versions where a higher version is on an ancestor of
+// the lower version's commit, meaning the counters went backwards as history
+// moved forwards. Each version is reported against the first such higher
+// version, and a commit that cannot be read is reported as well.
+func lintAncestry(r *git.Repository, entries []*listEntry) ([]lintIssue, error) {
+	var issues []lintIssue
+	commits := map[string]*object.Commit{}
+	unreadable := map[string]bool{}
+	commit := func(e *listEntry) *object.Commit {
+		if c, ok := commits[e.Commit]; ok || unreadable[e.Commit] {
+			return c
+		}
+		c, err := r.CommitObject(plumbing.NewHash(e.Commit))
+		if err != nil {
+			unreadable[e.Commit] = true
+			issues = append(issues, lintIssue{
+				Severity: SeverityError,
+				Check:    "ancestry",
+				Tags:     []string{e.Name},
+				Message:  fmt.Sprintf("cannot read commit %s of %s: %v", e.Commit, e.Name, err),
+			})
+			return nil
+		}
+		commits[e.Commit] = c
+		return c
+	}
+	ancestor := map[[2]string]bool{}
+	for i, lower := range entries {
+		lc := commit(lower)
+		if lc == nil {
+			continue
+		}
+		for _, higher := range entries[i+1:] {
+			if lower.Commit == higher.Commit {
+				continue
+			}
+			hc := commit(higher)
+			if hc == nil {
+				continue
+			}
+			key := [2]string{higher.Commit, lower.Commit}
+			backwards, ok := ancestor[key]
+			if !ok {
+				var err error
+				if backwards, err = hc.IsAncestor(lc); err != nil {
+					return nil, err
+				}
+				ancestor[key] = backwards
+			}
+			if backwards {
+				issues = append(issues, lintIssue{
+					Severity: SeverityError,
+					Check:    "ancestry",
+					Tags:     []string{lower.Name, higher.Name},
+					Message:  fmt.Sprintf("%s is on a later commit than %s but sorts before it", lower.Name, higher.Name),
+				})
+				break
+			}
+		}
+	}
+	return issues, nil
+}
+
+func writeLint(w io.Writer, issues []lintIssue) {
+	errs, warnings := 0, 0
+	for _, i := range issues {
+		fmt.Fprintf(w, "%s: [%s] %s\n", i.Severity, i.Check, i.Message)
+		if i.Severity == SeverityError {
+			errs++
+		} else {
+			warnings++
+		}
+	}
+	fmt.Fprintf(w, "%d error(s), %d warning(s)\n", errs, warnings)
+}
+
+type lintFlags struct {
+	strict *bool
+}
+
+// newLintFlags defines the flags of the lint subcommand.
+func newLintFlags() (*flag.FlagSet, *lintFlags) {
+	fs := newFlagSet("lint")
+	f := &lintFlags{
+		strict: fs.Bool("strict", false, "Treat warnings as errors"),
+	}
+	fs.StringVar(output, "output", *output, "Output format: text or json")
+	return fs, f
+}
+
+func runLint(args []string) {
+	fs, f := newLintFlags()
+	_ = fs.Parse(args)
+
+	r := openRepository()
+	issues, err := LintTags(r)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to lint tags: %v\n", err)
+		os.Exit(1)
+	}
+	if *output == OutputJSON {
+		if issues == nil {
+			issues = []lintIssue{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(issues); err != nil {
+			fmt.Fprintf(out, "Failed to write JSON output: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		writeLint(os.Stdout, issues)
+	}
+	for _, i := range issues {
+		if i.Severity == SeverityError || *f.strict {
+			os.Exit(1)
+		}
+	}
+}
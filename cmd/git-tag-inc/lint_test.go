@@ -0,0 +1,97 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLintTags(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	c2 := testCommit(t, r, dir, "two")
+	c3 := testCommit(t, r, dir, "three")
+
+	// a clean promotion flow
+	testTag(t, r, "v1.0.0-test.01", c1, false)
+	testTag(t, r, "v1.0.0-test.02", c2, false)
+	testTag(t, r, "v1.0.0-uat.02", c2, true)
+	testTag(t, r, "v1.0.0", c2, true)
+	issues, err := LintTags(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issues) != 0 {
+		t.Fatalf("expected no issues, got %#v", issues)
+	}
+
+	testTag(t, r, "V1.2.3", c3, false)
+	testTag(t, r, "v1.2", c3, false)
+	testTag(t, r, "v1.1.0-test1", c3, false)
+	testTag(t, r, "v1.1.0-test04", c3, false)
+	testTag(t, r, "v0.9.0", c3, false)
+	issues, err = LintTags(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := map[string][]string{}
+	for _, i := range issues {
+		found[i.Check] = append(found[i.Check], i.Severity+": "+i.Message)
+	}
+	for check, want := range map[string]string{
+		"near-miss":     "did you mean v1.2.3?",
+		"mixed-modes":   "semver and",
+		"padding":       "test counter of v1.1.0-test1 is not zero padded",
+		"shared-commit": "are both on commit",
+		"gap":           "jumps from v1.1.0-test1 to v1.1.0-test04",
+		"ancestry":      "error: v0.9.0 is on a later commit than v1.0.0-test.01",
+	} {
+		if !strings.Contains(strings.Join(found[check], "\n"), want) {
+			t.Errorf("%s: expected %q in %q", check, want, found[check])
+		}
+	}
+}
+
+func TestLintAncestryNonAdjacent(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	c2 := testCommit(t, r, dir, "two")
+
+	// a second root commit, unrelated to both
+	root, err := r.CommitObject(c1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unrelated := *root
+	unrelated.Message = "unrelated"
+	obj := r.Storer.NewEncodedObject()
+	if err := unrelated.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	c3, err := r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testTag(t, r, "v1.5.0", c2, false)
+	testTag(t, r, "v1.6.0", c3, false)
+	testTag(t, r, "v2.0.0", c1, false)
+	issues, err := LintTags(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found []string
+	for _, i := range issues {
+		if i.Check == "ancestry" {
+			found = append(found, i.Message)
+		}
+	}
+	if len(found) != 1 || found[0] != "v1.5.0 is on a later commit than v2.0.0 but sorts before it" {
+		t.Errorf("ancestry issues %q", found)
+	}
+}
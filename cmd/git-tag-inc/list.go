@@ -0,0 +1,252 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// listEntry is a single tag as interpreted by the tool.
+type listEntry struct {
+	Name      string         `json:"name"`
+	Tag       *gittaginc.Tag `json:"-"`
+	Mode      string         `json:"mode,omitempty"`
+	Commit    string         `json:"commit"`
+	Annotated bool           `json:"annotated"`
+	Date      *time.Time     `json:"tagger_date,omitempty"`
+	Valid     bool           `json:"valid"`
+}
+
+type listFilter struct {
+	Env     string
+	Stage   string
+	Min     *gittaginc.Tag
+	Max     *gittaginc.Tag
+	Prefix  string
+	Invalid bool
+}
+
+// validate rejects an --env or --stage that no tag could match, which
+// would otherwise list nothing.
+func (f *listFilter) validate() error {
+	for _, v := range []struct{ flag, value string }{{"env", f.Env}, {"stage", f.Stage}} {
+		if v.value == "" {
+			continue
+		}
+		known := false
+		for _, k := range flagValues[v.flag] {
+			known = known || k == v.value
+		}
+		if known {
+			continue
+		}
+		return fmt.Errorf("unknown --%s %q, expected one of %s", v.flag, v.value, strings.Join(flagValues[v.flag], ", "))
+	}
+	return nil
+}
+
+func (f *listFilter) match(e *listEntry) bool {
+	if f.Prefix != "" && !strings.HasPrefix(e.Name, f.Prefix) {
+		return false
+	}
+	if e.Tag == nil {
+		return f.Invalid && f.Env == "" && f.Stage == "" && f.Min == nil && f.Max == nil
+	}
+	switch f.Env {
+	case "":
+	case "none":
+		if e.Tag.Test != nil || e.Tag.Uat != nil {
+			return false
+		}
+	case "test":
+		if e.Tag.Test == nil {
+			return false
+		}
+	case "uat":
+		if e.Tag.Uat == nil {
+			return false
+		}
+	default:
+		return false
+	}
+	switch f.Stage {
+	case "":
+	case "none":
+		if e.Tag.Stage != nil {
+			return false
+		}
+	default:
+		if e.Tag.Stage == nil || e.Tag.StageName != f.Stage {
+			return false
+		}
+	}
+	if f.Min != nil && e.Tag.LessThan(f.Min) {
+		return false
+	}
+	if f.Max != nil && f.Max.LessThan(e.Tag) {
+		return false
+	}
+	return true
+}
+
+// resolveTagRef returns the commit a tag reference points at, peeling nested
+// annotated tags, along with its outermost annotated tag object, which is nil
+// for lightweight tags. A tag that does not end at a commit gives a zero hash.
+func resolveTagRef(r *git.Repository, ref *plumbing.Reference) (plumbing.Hash, *object.Tag, error) {
+	to, err := r.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		to = nil
+	} else if err != nil {
+		return plumbing.ZeroHash, nil, err
+	}
+	commit, err := gittaginc.PeelToCommit(r, ref.Hash())
+	if errors.Is(err, gittaginc.ErrNotCommit) {
+		return plumbing.ZeroHash, to, nil
+	}
+	return commit, to, err
+}
+
+// ListTags returns every tag accepted by the filter, version tags sorted by
+// LessThan followed by unparsable tags sorted by name.
+func ListTags(r *git.Repository, filter listFilter) ([]*listEntry, error) {
+	var valid, invalid []*listEntry
+	err := ForEachTagRef(r, func(ref *plumbing.Reference, t *gittaginc.Tag) error {
+		e := &listEntry{Name: ref.Name().Short(), Tag: t, Valid: t != nil}
+		if !filter.match(e) {
+			return nil
+		}
+		commit, to, err := resolveTagRef(r, ref)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", e.Name, err)
+		}
+		e.Commit = commit.String()
+		if to != nil {
+			e.Annotated = true
+			when := to.Tagger.When
+			e.Date = &when
+		}
+		if t != nil {
+			e.Mode = t.Mode
+			valid = append(valid, e)
+		} else {
+			invalid = append(invalid, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(valid, func(i, j int) bool {
+		return valid[i].Tag.LessThan(valid[j].Tag)
+	})
+	sort.Slice(invalid, func(i, j int) bool {
+		return invalid[i].Name < invalid[j].Name
+	})
+	return append(valid, invalid...), nil
+}
+
+func writeList(w io.Writer, entries []*listEntry) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TAG\tMODE\tCOMMIT\tANNOTATED\tDATE")
+	for _, e := range entries {
+		m := e.Mode
+		if !e.Valid {
+			m = "invalid"
+		}
+		date := "-"
+		if e.Date != nil {
+			date = e.Date.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%v\t%s\n", e.Name, m, e.Commit, e.Annotated, date)
+	}
+	return tw.Flush()
+}
+
+type listFlags struct {
+	env, stage, min, max, prefix *string
+	invalid                      *bool
+}
+
+// newListFlags defines the flags of the list subcommand.
+func newListFlags() (*flag.FlagSet, *listFlags) {
+	fs := newFlagSet("list")
+	f := &listFlags{
+		env:     fs.String("env", "", "Only show tags for environment: test, uat or none"),
+		stage:   fs.String("stage", "", "Only show tags for stage: alpha, beta, rc, next or none"),
+		min:     fs.String("min", "", "Only show tags at or above this version"),
+		max:     fs.String("max", "", "Only show tags at or below this version"),
+		prefix:  fs.String("prefix", "", "Only show tags whose name starts with this prefix"),
+		invalid: fs.Bool("invalid", false, "Also show tags that are not recognised as versions"),
+	}
+	fs.StringVar(output, "output", *output, "Output format: text or json")
+	return fs, f
+}
+
+func runList(args []string) {
+	fs, f := newListFlags()
+	_ = fs.Parse(args)
+
+	filter := listFilter{
+		Env:     strings.ToLower(*f.env),
+		Stage:   strings.ToLower(*f.stage),
+		Prefix:  *f.prefix,
+		Invalid: *f.invalid,
+	}
+	if err := filter.validate(); err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		os.Exit(1)
+	}
+	for _, bound := range []struct {
+		value string
+		dest  **gittaginc.Tag
+	}{{*f.min, &filter.Min}, {*f.max, &filter.Max}} {
+		if bound.value == "" {
+			continue
+		}
+		t := parser.ParseTag(bound.value)
+		if t == nil {
+			fmt.Fprintf(out, "Invalid version: %s\n", bound.value)
+			os.Exit(1)
+		}
+		*bound.dest = t
+	}
+
+	r := openRepository()
+	entries, err := ListTags(r, filter)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to list tags: %v\n", err)
+		os.Exit(1)
+	}
+	if *output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if entries == nil {
+			entries = []*listEntry{}
+		}
+		err = enc.Encode(entries)
+	} else {
+		err = writeList(os.Stdout, entries)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "Failed to write tags: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var testSignature = &object.Signature{
+	Name:  "Test",
+	Email: "test@example.com",
+	When:  time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC),
+}
+
+func newTestRepo(t *testing.T) (*git.Repository, string) {
+	t.Helper()
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r, dir
+}
+
+// setTestUser configures the user the binary tags as.
+func setTestUser(t *testing.T, r *git.Repository) {
+	t.Helper()
+	cfg, err := r.Config()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.User.Name = "Test"
+	cfg.User.Email = "test@example.com"
+	if err := r.SetConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testCommit(t *testing.T, r *git.Repository, dir, content string) plumbing.Hash {
+	t.Helper()
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.Add("file.txt"); err != nil {
+		t.Fatal(err)
+	}
+	h, err := w.Commit(content, &git.CommitOptions{Author: testSignature})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func testTag(t *testing.T, r *git.Repository, name string, h plumbing.Hash, annotated bool) {
+	t.Helper()
+	var opts *git.CreateTagOptions
+	if annotated {
+		opts = &git.CreateTagOptions{Message: name, Tagger: testSignature}
+	}
+	if _, err := r.CreateTag(name, h, opts); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestListTags(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	c2 := testCommit(t, r, dir, "two")
+	testTag(t, r, "v1.0.0-test.02", c1, true)
+	testTag(t, r, "v1.0.0-test.01", c1, false)
+	testTag(t, r, "v1.0.0-uat.02", c2, true)
+	testTag(t, r, "v0.9.0", c1, false)
+	testTag(t, r, "V1.2.3", c2, false)
+	uat, err := r.Tag("v1.0.0-uat.02")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a tag of a tag still lists the commit at the end of the chain
+	testTag(t, r, "v1.0.0-uat.03", uat.Hash(), true)
+
+	names := func(entries []*listEntry) string {
+		var s []string
+		for _, e := range entries {
+			s = append(s, e.Name)
+		}
+		return strings.Join(s, " ")
+	}
+
+	entries, err := ListTags(r, listFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(entries), "v0.9.0 v1.0.0-test.01 v1.0.0-test.02 v1.0.0-uat.02 v1.0.0-uat.03"; got != want {
+		t.Errorf("got %q want %q", got, want)
+	}
+	for _, e := range entries {
+		switch e.Name {
+		case "v1.0.0-test.02":
+			if !e.Annotated || e.Date == nil || e.Commit != c1.String() {
+				t.Errorf("unexpected annotated entry %#v", e)
+			}
+		case "v1.0.0-test.01":
+			if e.Annotated || e.Date != nil || e.Commit != c1.String() {
+				t.Errorf("unexpected lightweight entry %#v", e)
+			}
+		case "v1.0.0-uat.03":
+			if !e.Annotated || e.Commit != c2.String() {
+				t.Errorf("unexpected nested entry %#v", e)
+			}
+		}
+	}
+
+	filtered, err := ListTags(r, listFilter{Env: "test", Min: gittaginc.ParseTag("v1.0.0-test.02")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(filtered), "v1.0.0-test.02"; got != want {
+		t.Errorf("filtered got %q want %q", got, want)
+	}
+
+	withInvalid, err := ListTags(r, listFilter{Invalid: true, Prefix: "V"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(withInvalid) != 1 || withInvalid[0].Valid || withInvalid[0].Commit != c2.String() {
+		t.Errorf("unexpected invalid listing %#v", withInvalid)
+	}
+
+	var buf bytes.Buffer
+	if err := writeList(&buf, entries); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "v1.0.0-uat.02") || !strings.HasPrefix(buf.String(), "TAG") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestListFilterValidate(t *testing.T) {
+	for _, f := range []listFilter{{}, {Env: "uat"}, {Env: "none", Stage: "rc"}, {Stage: "none"}} {
+		if err := f.validate(); err != nil {
+			t.Errorf("%+v: unexpected error %v", f, err)
+		}
+	}
+	for _, f := range []listFilter{{Env: "foo"}, {Stage: "gamma"}, {Env: "test", Stage: "release"}} {
+		if err := f.validate(); err == nil {
+			t.Errorf("%+v: expected an error", f)
+		}
+	}
+}
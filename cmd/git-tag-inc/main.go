@@ -7,13 +7,14 @@
 package main
 
 import (
-	"bytes"
+	"context"
 	_ "embed"
 	"flag"
 	"fmt"
 	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"strings"
 	"text/template"
 	"time"
@@ -22,7 +23,6 @@ import (
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/pkg/errors"
 )
 
@@ -35,11 +35,27 @@ var (
 	repeating        = flag.Bool("repeating", false, "Allow new tags to repeat a previous")
 	allowBackwards   = flag.Bool("allow-backwards", false, "Allow numeric arguments to decrease version counters")
 	skipForwards     = flag.Bool("skip-forwards", false, "Automatically bump the patch when numeric arguments go backwards")
-	force            = flag.Bool("force", false, "Force the operation (implies --allow-backwards, --repeating, --ignore)")
+	force            = flag.Bool("force", false, "Force the operation (implies --allow-backwards, --repeating, --ignore and skips stage transition checks)")
+	interactive      = flag.Bool("i", false, "Choose the next version from a menu")
+	useCache         = flag.Bool("cache", false, "Cache parsed tags in .git/git-tag-inc/tag-cache between runs")
+	explain          = flag.Bool("explain", false, "Print why the highest tag was chosen and each step taken to increment it")
+	createRelease    = flag.Bool("release", false, "Create a release with notes for the new tag through the GitHub or Gitea API")
+	commitFile       = flag.Bool("commit", false, "With --source file, commit the rewritten version file")
+	tagFile          = flag.Bool("tag", false, "With --source file, commit the rewritten version file and tag the commit")
+	ciOutputs        = flag.Bool("ci", true, "Write version outputs for GitHub Actions or GitLab CI when running under them")
+	requireSignOff   = flag.Bool("require-sign-off", false, "Only tag uat or a release when HEAD already has the test or uat tag for the same version")
 	// TODO: consider supporting other naming modes such as "xyzzy",
 	// "hybrid" or "octarine" which some teams use internally.
-	mode = flag.String("mode", "auto", "Naming mode: auto, semver, legacy, or arraneous")
-	baseVersion      = flag.String("base-version", "", "String mode: explicit base version to increment. If '-' is provided, reads from stdin. Operates entirely offline and bypasses git repository checks.")
+	mode        = flag.String("mode", "auto", "Naming mode: auto, semver, legacy, or arraneous")
+	baseVersion = flag.String("base-version", "", "String mode: explicit base version to increment. If '-' is provided, reads from stdin. Operates entirely offline and bypasses git repository checks.")
+	output      = flag.String("output", OutputText, "Output format: text, json or docker-tags")
+	outputFile  = flag.String("output-file", "", "Also write the version outputs to this file as NAME=value lines")
+	separator   = flag.String("separator", "", "Separator between docker-tags, e.g. ',' (default a newline)")
+	repoPath    = flag.String("repo", ".", "Run in the repository at this path, or any directory inside it")
+	configPath  = flag.String("config", "", "Read settings from this file instead of "+gittaginc.ConfigFile+" at the top of the worktree")
+	releaseAPI  = flag.String("release-api", "", "API base URL for --release, e.g. https://gitea.example.com/api/v1 (default "+gittaginc.DefaultReleaseAPI+")")
+	source      = flag.String("source", sourceTags, "Where the version comes from: tags, or file:<path> for a file such as VERSION")
+	lineFlag    = flag.String("line", "", "Only bump within this maintenance line, e.g. 1.2, ignoring tags of other major.minor versions")
 
 	out io.Writer = os.Stderr
 )
@@ -54,323 +70,556 @@ var (
 	repo    = "https://github.com/arran4/git-tag-inc"
 )
 
+func init() {
+	flag.StringVar(repoPath, "C", *repoPath, "Same as --repo")
+}
+
 func main() {
 	flag.Usage = Usage
 	flag.Parse()
 
 	args := flag.Args()
-	hasDash := false
-	var filteredArgs []string
-	for _, arg := range args {
-		if arg == "-" {
-			hasDash = true
-		} else {
-			filteredArgs = append(filteredArgs, arg)
+	name := defaultSubcommand
+	if len(args) > 0 {
+		if args[0] == completeCommand {
+			runComplete(args[1:])
+			return
+		}
+		if _, ok := subcommands[args[0]]; ok {
+			name, args = args[0], args[1:]
+		}
+	}
+	// flags that used to select another operation still do
+	if name == defaultSubcommand {
+		switch {
+		case *showVersion:
+			name = "version"
+		case *baseVersion != "" || hasDash(args):
+			name = "calc"
+		case *printVersionOnly:
+			name = "preview"
 		}
 	}
+	subcommands[name].run(args)
+}
 
-	baseVersionStr := *baseVersion
-	if hasDash || baseVersionStr == "-" {
-		b, err := io.ReadAll(os.Stdin)
-		if err != nil {
-			log.Printf("Failed to read from stdin: %v", err)
-			os.Exit(1)
+func hasDash(args []string) bool {
+	for _, arg := range args {
+		if arg == "-" {
+			return true
 		}
-		baseVersionStr = strings.TrimSpace(string(b))
 	}
+	return false
+}
+
+// bumpFlagNames are the global flags bump and preview also accept after the
+// subcommand.
+var bumpFlagNames = []string{"verbose", "dry", "ignore", "repeating", "allow-backwards", "skip-forwards", "force", "i", "cache", "require-sign-off", "explain", "mode", "output", "separator", "output-file", "ci", "config", "line", "release", "release-api", "source", "commit", "tag"}
+
+// newBumpFlags defines the flags of bump and preview.
+func newBumpFlags(name string) *flag.FlagSet {
+	fs := newFlagSet(name)
+	shareFlags(fs, bumpFlagNames...)
+	return fs
+}
 
+// setupRun applies --force and --output, which bump, preview and calc share.
+func setupRun() {
 	if *force {
 		*allowBackwards = true
 		*repeating = true
 		*ignore = true
 	}
 
-	if *printVersionOnly {
-		*dry = true
+	switch *output {
+	case OutputText, OutputDockerTags:
+	case OutputJSON:
+		report = &runReport{}
+	default:
+		fmt.Fprintf(out, "Unknown output format: %s\n", *output)
+		os.Exit(1)
+	}
+
+	if *printVersionOnly || report != nil {
+		if *printVersionOnly {
+			*dry = true
+		}
 		out = io.Discard
 		log.SetOutput(io.Discard)
 	}
-	if *showVersion {
-		printVersion()
-		return
-	}
 	if !*verbose {
 		log.SetFlags(0)
 	}
 	if *verbose {
 		fmt.Fprintf(out, "Version: %s (%s) by %s commit %s\n", version, date, builtBy, commit)
 	}
-	flags := gittaginc.CommandsToFlags(filteredArgs, *mode)
-	if !flags.Valid || (!flags.Major && !flags.Minor && !flags.Patch && !flags.Release && flags.Env == "" && flags.Stage == "") {
+}
+
+// validCommands parses the bump commands, failing or printing usage when
+// they are invalid or there are none.
+func validCommands(args []string) gittaginc.CmdFlags {
+	flags := parser.CommandsToFlags(args, *mode)
+	if !flags.Valid || flags.Empty() {
+		if report != nil {
+			fail(gittaginc.ErrCodeInvalidArguments, "Invalid or missing commands: %s", strings.Join(args, " "))
+		}
 		Usage()
-		return
+		os.Exit(0)
 	}
+	return flags
+}
 
-	if baseVersionStr != "" {
-		t := gittaginc.ParseTag(baseVersionStr)
-		if t == nil {
-			fmt.Fprintf(out, "Invalid base version tag: %s\n", baseVersionStr)
-			os.Exit(1)
-		}
-		if *mode != "auto" {
-			t.Mode = *mode
+func runPreview(args []string) {
+	fs := newBumpFlags("preview")
+	_ = fs.Parse(args)
+	*printVersionOnly = true
+	bump(fs.Args())
+}
+
+func runBump(args []string) {
+	fs := newBumpFlags("bump")
+	_ = fs.Parse(args)
+	bump(fs.Args())
+}
+
+// newCalcFlags defines the flags of calc.
+func newCalcFlags() *flag.FlagSet {
+	fs := newFlagSet("calc")
+	shareFlags(fs, "base-version", "allow-backwards", "skip-forwards", "force", "mode", "output", "separator", "output-file", "ci", "verbose", "config", "explain")
+	return fs
+}
+
+// runCalc increments a version given as the first argument, with
+// --base-version or on stdin, without touching a repository.
+func runCalc(args []string) {
+	fs := newCalcFlags()
+	_ = fs.Parse(args)
+	// before anything is parsed, as it may add qualifiers
+	cfg := loadConfig(nil)
+
+	base := *baseVersion
+	var cmds []string
+	for _, arg := range fs.Args() {
+		if arg == "-" {
+			base = "-"
+		} else {
+			cmds = append(cmds, arg)
 		}
-		if err := t.Increment(flags, *allowBackwards, *skipForwards); err != nil {
-			fmt.Fprintf(out, "%v\n", err)
-			os.Exit(1)
+	}
+	if base == "" && len(cmds) > 0 && parser.ParseTag(cmds[0]) != nil {
+		base, cmds = cmds[0], cmds[1:]
+	}
+	if base == "-" {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fail(ErrCodeStdinRead, "Failed to read from stdin: %v", err)
 		}
-		// Ensure output goes directly to stdout, without any prefixes like "Largest:" or "Creating".
-		fmt.Println(t.String())
-		return
+		base = strings.TrimSpace(string(b))
 	}
 
-	r, err := git.PlainOpen(".")
+	setupRun()
+	if base == "" {
+		fail(ErrCodeInvalidBaseVersion, "calc needs a base version, as an argument, with --base-version or on stdin")
+	}
+	flags := validCommands(cmds)
+	t := parser.ParseTag(base)
+	if t == nil {
+		fail(ErrCodeInvalidBaseVersion, "Invalid base version tag: %s", base)
+	}
+	if *mode != "auto" {
+		t.Mode = *mode
+	}
+	if report != nil {
+		report.DryRun = true
+		report.setPrevious(t)
+	}
+	err := t.IncrementWithOptions(flags, gittaginc.IncrementOptions{
+		AllowBackwards: *allowBackwards,
+		SkipForwards:   *skipForwards,
+		Transitions:    cfg.Transitions,
+		Force:          *force,
+		Qualifiers:     parser.Qualifiers(),
+		Explain:        explainWriter(),
+	})
 	if err != nil {
-		if errors.Is(err, git.ErrRepositoryNotExists) {
-			log.Printf("Error: %v. Are you in a git repository?", err)
-		} else {
-			log.Printf("Error opening repository: %v", err)
-		}
-		os.Exit(1)
+		fail(gittaginc.ErrCodeIncrement, "%v", err)
 	}
+	writeCIOutputs(base, t, "", false)
+	if report != nil {
+		report.setTag(t)
+		writeReport(os.Stdout)
+		return
+	}
+	if *output == OutputDockerTags {
+		writeDockerTags(os.Stdout, t, nil)
+		return
+	}
+	// Ensure output goes directly to stdout, without any prefixes like "Largest:" or "Creating".
+	fmt.Println(t.String())
+}
 
-	var tagger *object.Signature
-	if !*printVersionOnly {
-		cfg, cfgErr := r.ConfigScoped(config.SystemScope)
-		if cfgErr == nil {
-			if cfg.User.Name == "" || cfg.User.Email == "" {
-				fmt.Fprintf(out, "git user.name or user.email not configured\n")
-				fmt.Fprintf(out, "Run `git config --global user.name \"Your Name\"` and `git config --global user.email \"you@example.com\"`\n")
-				os.Exit(1)
-				return
-			}
-			tagger = &object.Signature{
-				Name:  cfg.User.Name,
-				Email: cfg.User.Email,
-				When:  time.Now(),
-			}
-		}
+func runVersion(args []string) {
+	fs := subcommands["version"].flags()
+	_ = fs.Parse(args)
+	printVersion()
+}
+
+// bump tags HEAD with the next version, or only prints it for preview and
+// --dry.
+func bump(filteredArgs []string) {
+	setupRun()
+	if *interactive && (len(filteredArgs) > 0 || report != nil || *printVersionOnly) {
+		fail(gittaginc.ErrCodeInvalidArguments, "-i takes no commands and cannot be combined with --output json or preview")
+	}
+	// with nothing to do show the usage, even outside a repository
+	if !*interactive && len(filteredArgs) == 0 {
+		validCommands(filteredArgs)
+	}
+	if report != nil {
+		report.DryRun = *dry
 	}
 
-	if !*ignore {
-		wt, err := r.Worktree()
+	r := openRepository()
+	cfg := repoConfig
+	// checked once the configuration has added any qualifier commands
+	if !*interactive {
+		validCommands(filteredArgs)
+	}
+	var src gittaginc.TagSource = newTagSource(r)
+	if *useCache {
+		cached, err := gittaginc.NewCachedTagSource(r)
 		if err != nil {
-			log.Printf("Failed to get worktree: %v", err)
-			os.Exit(1)
+			fail(gittaginc.ErrCodeTagLookup, "Tag cache: %s", err)
 		}
-		s, err := wt.Status()
+		cached.Parser = parser
+		src = cached
+	}
+	if *verbose {
+		src = verboseSource{src}
+	}
+	versionFile := versionFileSource()
+	if versionFile != "" && (*interactive || *lineFlag != "") {
+		fail(gittaginc.ErrCodeInvalidArguments, "--source file cannot be combined with -i or --line")
+	}
+	var line *gittaginc.Line
+	if versionFile == "" {
+		line = resolveLine(r, cfg)
+	}
+
+	if *interactive {
+		pickSrc := src
+		if line != nil {
+			pickSrc = gittaginc.NewLineTagSource(src, line)
+		}
+		p, err := newPicker(r, pickSrc, *mode, gittaginc.IncrementOptions{
+			AllowBackwards: *allowBackwards,
+			SkipForwards:   *skipForwards,
+			Transitions:    cfg.Transitions,
+			Force:          *force,
+		})
 		if err != nil {
-			log.Printf("Failed to get worktree status: %v", err)
-			os.Exit(1)
+			fail(gittaginc.ErrCodeTagLookup, "%v", err)
 		}
-		if !s.IsClean() {
-			fmt.Fprintf(out, "There are uncommited changes in thils repo.\n")
-			os.Exit(1)
+		cmds, err := pickCommands(p, os.Stdin, out)
+		if errors.Is(err, errCancelled) {
+			fmt.Fprintln(out, "Cancelled.")
 			return
+		} else if err != nil {
+			fail(gittaginc.ErrCodeIncrement, "%v", err)
 		}
+		filteredArgs = cmds
 	}
 
-	currentHash, err := GetHash(r, nil)
-	if err != nil {
-		log.Printf("Failed to get current hash: %v", err)
-		os.Exit(1)
+	var tagger *gittaginc.Signature
+	if !*printVersionOnly {
+		tagger = loadTagger(r)
 	}
-	if !*repeating && currentHash != "" {
-		lastSimilar, err := FindHighestSimilarVersionTag(r, flags.Env)
-		if err != nil {
-			fmt.Fprintf(out, "Failed to find highest similar version tag: %v", err)
-			os.Exit(1)
-		}
-		if lastSimilar != nil {
-			lastSimilarHash, err := GetHash(r, lastSimilar)
-			if err != nil {
-				switch {
-				case errors.Is(err, plumbing.ErrObjectNotFound):
-				default:
-					log.Printf("Failed to get hash for similar version: %v", err)
-					os.Exit(1)
-				}
-			} else {
-				if len(lastSimilarHash) > 0 && lastSimilarHash == currentHash {
-					fmt.Fprintf(out, "Hash is the same for this and previous tag: (%s) %s and %s\n", lastSimilar, lastSimilarHash, currentHash)
-					os.Exit(1)
-					return
-				}
-			}
+	var releases *gittaginc.ReleaseClient
+	var webhooks []webhookTarget
+	if !*printVersionOnly {
+		if *createRelease {
+			releases = newReleaseClient(r, cfg)
 		}
+		webhooks = loadWebhooks(cfg)
+	}
+	if versionFile != "" {
+		bumpVersionFile(r, src, versionFile, filteredArgs, cfg, tagger, releases, webhooks)
+		return
 	}
 
-	highest, err := FindHighestVersionTag(r)
+	res, err := gittaginc.Bump(context.Background(), src, gittaginc.BumpOptions{
+		Commands:       filteredArgs,
+		Mode:           *mode,
+		AllowBackwards: *allowBackwards,
+		SkipForwards:   *skipForwards,
+		Repeating:      *repeating,
+		RequireClean:   !*ignore,
+		Force:          *force,
+		Transitions:    cfg.Transitions,
+		RequireSignOff: *requireSignOff || cfg.RequireSignOff,
+		Line:           line,
+		Explain:        explainWriter(),
+		Dry:            *dry,
+		Tagger:         tagger,
+	})
+	if report != nil {
+		report.Target = res.Target
+		report.setPrevious(res.Previous)
+		report.setTag(res.Tag)
+	}
+	if res.Previous != nil {
+		fmt.Fprintf(out, "Largest: %s (%s)\n", res.Previous, res.Target)
+	}
 	if err != nil {
-		fmt.Fprintf(out, "Failed to find highest version tag: %v", err)
-		os.Exit(1)
+		code := gittaginc.ErrorCode(err)
+		if code == "" {
+			code = gittaginc.ErrCodeTagCreate
+		}
+		fail(code, "%v", err)
 	}
-
-	fmt.Fprintf(out, "Largest: %s (%s)\n", highest, currentHash)
-
-	if err := highest.Increment(flags, *allowBackwards, *skipForwards); err != nil {
-		fmt.Fprintf(out, "%v\n", err)
-		os.Exit(1)
+	if res.Retries > 0 {
+		fmt.Fprintf(out, "Tag was created concurrently, recomputed %d time(s)\n", res.Retries)
 	}
-
-	fmt.Fprintf(out, "Creating %s\n", highest)
-	if *printVersionOnly {
-		fmt.Println(highest.String())
+	fmt.Fprintf(out, "Creating %s\n", res.Tag)
+	writeCIOutputs(previousVersion(res.Previous), res.Tag, res.Target, res.Created)
+	if *printVersionOnly && report == nil && *output != OutputDockerTags {
+		fmt.Println(res.Tag.String())
 		return
 	}
+	if *dry {
+		fmt.Fprintf(out, "Dry run finished.\n")
+	} else {
+		tagCreated(r, res, tagger, webhooks, releases)
+	}
+	if report != nil {
+		writeReport(os.Stdout)
+	}
+	if *output == OutputDockerTags {
+		existing, err := gittaginc.VersionTags(src, *mode)
+		if err != nil {
+			fail(gittaginc.ErrCodeTagLookup, "Failed to list tags: %v", err)
+		}
+		writeDockerTags(os.Stdout, res.Tag, existing)
+	}
+}
 
-	h, err := r.Head()
-	if err != nil {
-		log.Printf("Failed to get HEAD: %v", err)
-		os.Exit(1)
+// tagCreated follows up on a new tag the same way for every command that
+// creates one: it is recorded in the journal for undo, webhooks are notified
+// and, with --release, its release is created.
+func tagCreated(r *git.Repository, res gittaginc.BumpResult, tagger *gittaginc.Signature, webhooks []webhookTarget, releases *gittaginc.ReleaseClient) {
+	if err := appendJournal(r, journalEntry{
+		Tag:      res.Tag.String(),
+		Commit:   res.Target,
+		Previous: res.Previous.String(),
+		Created:  time.Now(),
+	}); err != nil {
+		fmt.Fprintf(out, "Failed to record %s in the tag journal: %v\n", res.Tag, err)
 	}
-	if !*dry {
-		_, err = r.CreateTag(highest.String(), h.Hash(), &git.CreateTagOptions{
-			Message: highest.String(),
-			Tagger:  tagger,
-		})
-	} else {
-		fmt.Fprintf(out, "Dry run finished.\n")
+	notifyWebhooks(r, webhooks, res, tagger)
+	if releases != nil {
+		publishRelease(r, releases, res)
 	}
+}
+
+// explainWriter is where --explain writes. It is always stderr, so the
+// explanation shows even when preview or --output json keep stdout clean.
+func explainWriter() io.Writer {
+	if !*explain {
+		return nil
+	}
+	return os.Stderr
+}
+
+// openRepository opens the repository containing --repo, searching parent
+// directories for .git and following linked worktrees to their common
+// directory the way git does, and loads its configuration into repoConfig.
+func openRepository() *git.Repository {
+	r, err := git.PlainOpenWithOptions(*repoPath, &git.PlainOpenOptions{
+		DetectDotGit:          true,
+		EnableDotGitCommonDir: true,
+	})
 	if err != nil {
-		log.Printf("Failed to create tag: %v", err)
-		os.Exit(1)
+		if errors.Is(err, git.ErrRepositoryNotExists) {
+			fail(ErrCodeRepositoryNotFound, "Error: %v. Are you in a git repository?", err)
+		}
+		fail(ErrCodeRepositoryOpen, "Error opening repository: %v", err)
 	}
+	repoConfig = loadConfig(r)
+	return r
 }
 
-func GetHash(r *git.Repository, lastSimilar *gittaginc.Tag) (string, error) {
-	var err error
-	var ref *plumbing.Reference
-	var to *object.Tag
-	if lastSimilar != nil {
-		var hash plumbing.Hash
-		if lastSimilar.Hash != "" {
-			hash = plumbing.NewHash(lastSimilar.Hash)
-		} else {
-			ref, err = r.Tag(lastSimilar.String())
-			if err == git.ErrTagNotFound {
-				return "", nil
-			} else if err != nil {
-				return "", err
-			}
-			hash = ref.Hash()
+// repoConfig is the configuration openRepository loaded.
+var repoConfig = &gittaginc.Config{}
+
+// parser reads tags and commands with the qualifiers loadConfig found.
+var parser *gittaginc.Parser
+
+// newTagSource returns a source for r that parses tags with parser.
+func newTagSource(r *git.Repository) *gittaginc.GoGitTagSource {
+	src := gittaginc.NewGoGitTagSource(r)
+	src.Parser = parser
+	return src
+}
+
+// loadConfig reads --config, or ConfigFile at the top of r's worktree when
+// there is one, and sets parser to read its qualifiers. Without a repository only
+// --config is read.
+func loadConfig(r *git.Repository) *gittaginc.Config {
+	path := *configPath
+	if path == "" {
+		if r == nil {
+			return &gittaginc.Config{}
 		}
-		to, err = r.TagObject(hash)
-		if err == git.ErrTagNotFound {
-			return "", nil
-		} else if err != nil {
-			return "", err
+		wt, err := r.Worktree()
+		if err != nil {
+			// bare repositories have nowhere to keep the file
+			return &gittaginc.Config{}
 		}
-		return to.Target.String(), nil
-	} else {
-		ref, err = r.Head()
-		if err == git.ErrTagNotFound {
-			return "", nil
-		} else if err != nil {
-			return "", err
+		path = filepath.Join(wt.Filesystem.Root(), gittaginc.ConfigFile)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return &gittaginc.Config{}
 		}
-		return ref.Hash().String(), nil
 	}
+	cfg, err := gittaginc.LoadConfig(path)
+	if err != nil {
+		fail(ErrCodeConfig, "Failed to read config: %v", err)
+	}
+	p, err := gittaginc.NewParser(cfg.Qualifiers)
+	if err != nil {
+		fail(ErrCodeConfig, "Failed to read config: %v", err)
+	}
+	parser = p
+	return cfg
 }
 
-func FindHighestSimilarVersionTag(r *git.Repository, env string) (*gittaginc.Tag, error) {
-	t, err := FindHVersionTag(r, func(last, current *gittaginc.Tag) bool {
-		if env == "test" && current.Test == nil {
-			return false
-		}
-		if env == "uat" && current.Uat == nil {
-			return false
-		}
-		if env == "" && (current.Uat != nil || current.Test != nil) {
-			return false
+// resolveLine returns the maintenance line given with --line or, failing
+// that, named by the checked out branch when it matches the configured
+// line_branch pattern. It is nil when neither applies.
+func resolveLine(r *git.Repository, cfg *gittaginc.Config) *gittaginc.Line {
+	if *lineFlag != "" {
+		line, err := gittaginc.ParseLine(*lineFlag)
+		if err != nil {
+			fail(gittaginc.ErrCodeInvalidArguments, "%v", err)
 		}
-		return last.LessThan(current)
-	})
-	return t, err
+		return line
+	}
+	if cfg.LineBranch == "" {
+		return nil
+	}
+	head, err := r.Head()
+	if err != nil || !head.Name().IsBranch() {
+		// a detached HEAD is on no branch
+		return nil
+	}
+	branchName := head.Name().Short()
+	line, err := gittaginc.LineFromBranch(cfg.LineBranch, branchName)
+	if err != nil {
+		fail(ErrCodeConfig, "Failed to read config: %v", err)
+	}
+	if line != nil {
+		fmt.Fprintf(out, "Line: %s (from branch %s)\n", line, branchName)
+	}
+	return line
 }
 
-func FindHighestVersionTag(r *git.Repository) (*gittaginc.Tag, error) {
-	t, err := FindHVersionTag(r, func(last, current *gittaginc.Tag) bool {
-		return last.LessThan(current)
-	})
-	return t, err
+// loadTagger returns the signature for annotated tags from the git
+// configuration, exiting when user.name or user.email is missing.
+func loadTagger(r *git.Repository) *gittaginc.Signature {
+	cfg, err := r.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return nil
+	}
+	if cfg.User.Name == "" || cfg.User.Email == "" {
+		fail(ErrCodeTaggerNotSet, "git user.name or user.email not configured\n"+
+			"Run `git config --global user.name \"Your Name\"` and `git config --global user.email \"you@example.com\"`")
+	}
+	return &gittaginc.Signature{
+		Name:  cfg.User.Name,
+		Email: cfg.User.Email,
+		When:  time.Now(),
+	}
+}
+
+// verboseSource prints each tag reference as it is listed.
+type verboseSource struct {
+	gittaginc.TagSource
 }
 
-func FindHVersionTag(r *git.Repository, stop func(last, current *gittaginc.Tag) bool) (*gittaginc.Tag, error) {
-	iter, err := r.Tags()
+// IsClean forwards to the wrapped source so Bump can still check the worktree.
+func (s verboseSource) IsClean() (bool, error) {
+	ws, ok := s.TagSource.(gittaginc.WorktreeStatus)
+	if !ok {
+		return false, fmt.Errorf("tag source cannot report worktree status")
+	}
+	return ws.IsClean()
+}
+
+// ParsedTags prints the tag references as they are listed, using the parsed
+// tags of the wrapped source when it has them, such as with --cache.
+func (s verboseSource) ParsedTags() ([]*gittaginc.Tag, error) {
+	refs, err := s.Tags()
 	if err != nil {
 		return nil, err
 	}
-	var startMode string
-	if *mode == "auto" {
-		startMode = gittaginc.ModeSemver
-	} else {
-		startMode = *mode
+	if _, ok := s.TagSource.(gittaginc.ParsedTagSource); !ok {
+		return gittaginc.SourceParser(s.TagSource).ParseTagRefs(refs), nil
 	}
-	var highest *gittaginc.Tag = &gittaginc.Tag{Mode: startMode}
-	if err := iter.ForEach(func(ref *plumbing.Reference) error {
+	return gittaginc.VersionTags(s.TagSource, *mode)
+}
+
+// TagParser forwards to the wrapped source.
+func (s verboseSource) TagParser() *gittaginc.Parser {
+	return gittaginc.SourceParser(s.TagSource)
+}
+
+// PeelTag forwards to the wrapped source.
+func (s verboseSource) PeelTag(hash string) (string, error) {
+	return gittaginc.PeelTag(s.TagSource, hash)
+}
+
+func (s verboseSource) Tags() ([]gittaginc.TagRef, error) {
+	refs, err := s.TagSource.Tags()
+	for _, ref := range refs {
+		fmt.Fprintf(out, "Ref: refs/tags/%s\n", ref.Name)
+	}
+	return refs, err
+}
+
+// ForEachTagRef calls fn for every tag reference in the repository along with
+// its parsed version, which is nil when the name is not a version tag.
+func ForEachTagRef(r *git.Repository, fn func(ref *plumbing.Reference, t *gittaginc.Tag) error) error {
+	refs, err := newTagSource(r).Tags()
+	if err != nil {
+		return err
+	}
+	for _, tr := range refs {
+		ref := plumbing.NewHashReference(plumbing.NewTagReferenceName(tr.Name), plumbing.NewHash(tr.Hash))
 		if *verbose {
 			fmt.Fprintf(out, "Ref: %s\n", ref.Name())
 		}
-		t := gittaginc.ParseTag(ref.Name().Short())
-		if t == nil {
-			return nil
-		}
-		if *mode != "auto" {
-			t.Mode = *mode
+		t := parser.ParseTag(tr.Name)
+		if t != nil {
+			if *mode != "auto" {
+				t.Mode = *mode
+			}
+			t.Hash = tr.Hash
 		}
-		t.Hash = ref.Hash().String()
-		if stop(highest, t) {
-			highest = t
+		if err := fn(ref, t); err != nil {
+			return err
 		}
-		return nil
-	}); err != nil {
-		return nil, err
 	}
-	return highest, nil
+	return nil
 }
 
 //go:embed usage.txt
 var usageText string
 
 func Usage() {
-	out := flag.CommandLine.Output()
-
-	var buf bytes.Buffer
-	flag.VisitAll(func(f *flag.Flag) {
-		fmt.Fprintf(&buf, "  -%s", f.Name)
-		name, usage := flag.UnquoteUsage(f)
-		if len(name) > 0 {
-			fmt.Fprintf(&buf, " %s", name)
-		}
-		if len(usage) > 24 {
-			fmt.Fprintf(&buf, "\n    \t")
-		} else {
-			fmt.Fprintf(&buf, "\t")
-		}
-		fmt.Fprintf(&buf, "%s", usage)
-		if f.DefValue != "" {
-			fmt.Fprintf(&buf, " (default %s)", f.DefValue)
-		}
-		fmt.Fprint(&buf, "\n")
-	})
-
 	t, err := template.New("usage").Parse(usageText)
 	if err != nil {
 		panic(err)
 	}
-
-	data := struct {
-		ProgramName     string
-		Flags           string
-		IsArraneousMode bool
-	}{
-		ProgramName:     os.Args[0],
-		Flags:           buf.String(),
-		IsArraneousMode: *mode == gittaginc.ModeArraneous,
-	}
-
-	if err := t.Execute(out, data); err != nil {
+	if err := t.Execute(flag.CommandLine.Output(), newUsageData(flag.CommandLine)); err != nil {
 		panic(err)
 	}
 }
@@ -7,12 +7,21 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 )
 
 func TestUsage(t *testing.T) {
@@ -28,23 +37,47 @@ func TestUsage(t *testing.T) {
 	}
 }
 
-func TestMain_NoGitRepo(t *testing.T) {
-	// Build the binary
-	tempDir, err := os.MkdirTemp("", "git-tag-inc-build")
-	if err != nil {
-		t.Fatalf("Failed to create temp dir: %v", err)
-	}
-	defer os.RemoveAll(tempDir)
+var (
+	binaryOnce sync.Once
+	binaryDir  string
+	binaryPath string
+	binaryErr  error
+)
 
-	exeName := "git-tag-inc"
-	if runtime.GOOS == "windows" {
-		exeName += ".exe"
+func TestMain(m *testing.M) {
+	code := m.Run()
+	if binaryDir != "" {
+		_ = os.RemoveAll(binaryDir)
 	}
-	exePath := filepath.Join(tempDir, exeName)
-	cmdBuild := exec.Command("go", "build", "-o", exePath, ".")
-	if out, err := cmdBuild.CombinedOutput(); err != nil {
-		t.Fatalf("Failed to build git-tag-inc: %v\nOutput: %s", err, out)
+	os.Exit(code)
+}
+
+// buildBinary builds the command once for all the tests that run it.
+func buildBinary(t *testing.T) string {
+	t.Helper()
+	binaryOnce.Do(func() {
+		if binaryDir, binaryErr = os.MkdirTemp("", "git-tag-inc-test"); binaryErr != nil {
+			return
+		}
+		exeName := "git-tag-inc"
+		if runtime.GOOS == "windows" {
+			exeName += ".exe"
+		}
+		exePath := filepath.Join(binaryDir, exeName)
+		if out, err := exec.Command("go", "build", "-o", exePath, ".").CombinedOutput(); err != nil {
+			binaryErr = fmt.Errorf("%v\nOutput: %s", err, out)
+			return
+		}
+		binaryPath = exePath
+	})
+	if binaryErr != nil {
+		t.Fatalf("Failed to build git-tag-inc: %v", binaryErr)
 	}
+	return binaryPath
+}
+
+func TestMain_NoGitRepo(t *testing.T) {
+	exePath := buildBinary(t)
 
 	// Create a directory that is NOT a git repo
 	nonGitDir, err := os.MkdirTemp("", "non-git-repo")
@@ -78,3 +111,309 @@ func TestMain_NoGitRepo(t *testing.T) {
 		t.Errorf("Expected output to contain %q, got: %q", expected, outStr)
 	}
 }
+
+func TestMain_JSONOutput(t *testing.T) {
+	exePath := buildBinary(t)
+
+	t.Run("string mode", func(t *testing.T) {
+		cmd := exec.Command(exePath, "--output", "json", "--base-version", "v1.2.3-test.04", "uat")
+		cmd.Dir = t.TempDir()
+		stdout, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var got runReport
+		if err := json.Unmarshal(stdout, &got); err != nil {
+			t.Fatalf("invalid JSON %q: %v", stdout, err)
+		}
+		if got.Previous != "v1.2.3-test.04" || got.Tag != "v1.2.3-uat.04" {
+			t.Errorf("got previous %s tag %s", got.Previous, got.Tag)
+		}
+		if got.Mode != "semver" || !got.DryRun || len(got.Errors) != 0 {
+			t.Errorf("unexpected report %#v", got)
+		}
+		if got.Components == nil || got.Components.Env != "uat" || got.Components.EnvNum == nil || *got.Components.EnvNum != 4 || got.Components.Patch != 3 {
+			t.Errorf("unexpected components %#v", got.Components)
+		}
+		// a dry run pushes nothing and tags are never signed
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(stdout, &fields); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"pushed", "signed"} {
+			if string(fields[name]) != "false" {
+				t.Errorf("%q is %s in %s", name, fields[name], stdout)
+			}
+		}
+	})
+
+	t.Run("error code", func(t *testing.T) {
+		cmd := exec.Command(exePath, "--output", "json", "patch")
+		cmd.Dir = t.TempDir()
+		stdout, err := cmd.Output()
+		if err == nil {
+			t.Fatalf("expected non-zero exit")
+		}
+		var got runReport
+		if err := json.Unmarshal(stdout, &got); err != nil {
+			t.Fatalf("invalid JSON %q: %v", stdout, err)
+		}
+		if len(got.Errors) != 1 || got.Errors[0].Code != ErrCodeRepositoryNotFound {
+			t.Errorf("unexpected errors %#v", got.Errors)
+		}
+	})
+}
+
+func TestMain_RepositoryLocation(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testCommit(t, r, dir, "two")
+	testTag(t, r, "v1.0.0", c1, false)
+	sub := filepath.Join(dir, "a", "b")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	next := func(t *testing.T, cwd string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command(exePath, append(args, "--print-version-only", "patch")...)
+		cmd.Dir = cwd
+		stdout, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return strings.TrimSpace(string(stdout))
+	}
+
+	t.Run("subdirectory", func(t *testing.T) {
+		if got := next(t, sub); got != "v1.0.1" {
+			t.Errorf("got %q", got)
+		}
+	})
+	t.Run("-C", func(t *testing.T) {
+		if got := next(t, t.TempDir(), "-C", sub); got != "v1.0.1" {
+			t.Errorf("got %q", got)
+		}
+		if got := next(t, t.TempDir(), "--repo", dir); got != "v1.0.1" {
+			t.Errorf("got %q", got)
+		}
+	})
+	t.Run("linked worktree", func(t *testing.T) {
+		if _, err := exec.LookPath("git"); err != nil {
+			t.Skip("git is not installed")
+		}
+		wt := filepath.Join(t.TempDir(), "wt")
+		cmd := exec.Command("git", "worktree", "add", "--detach", wt)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git worktree add: %v\n%s", err, out)
+		}
+		if got := next(t, wt); got != "v1.0.1" {
+			t.Errorf("got %q", got)
+		}
+	})
+}
+
+func TestMain_StageTransitions(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testCommit(t, r, dir, "two")
+	testTag(t, r, "v1.0.0-rc.03", c1, false)
+
+	next := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		cmd := exec.Command(exePath, append([]string{"preview"}, args...)...)
+		cmd.Dir = dir
+		stdout, err := cmd.Output()
+		return strings.TrimSpace(string(stdout)), err
+	}
+
+	if got, err := next(t, "alpha"); err == nil {
+		t.Errorf("alpha after rc was allowed: %q", got)
+	}
+	if got, err := next(t, "--force", "alpha"); err != nil || got != "v1.0.1-alpha.01" {
+		t.Errorf("--force alpha got %q, %v", got, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, gittaginc.ConfigFile), []byte(`{"transitions": {"rc": ["alpha"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := next(t, "alpha"); err != nil || got != "v1.0.1-alpha.01" {
+		t.Errorf("configured alpha got %q, %v", got, err)
+	}
+	if got, err := next(t, "patch"); err == nil {
+		t.Errorf("configured release was allowed: %q", got)
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.json")
+	if err := os.WriteFile(bad, []byte(`{"transitions": {"rc": ["gamma"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := next(t, "--config", bad, "rc"); err == nil {
+		t.Errorf("invalid config was accepted: %q", got)
+	}
+}
+
+func TestMain_Qualifiers(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testCommit(t, r, dir, "two")
+	testTag(t, r, "v1.4.2-hotfix.1", c1, false)
+	testTag(t, r, "v1.4.2", c1, false)
+	config := `{"qualifiers": [{"name": "hotfix", "rank": 1}]}`
+	if err := os.WriteFile(filepath.Join(dir, gittaginc.ConfigFile), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(exePath, args...)
+		cmd.Dir = dir
+		stdout, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return strings.TrimSpace(string(stdout))
+	}
+	if got := run("preview", "hotfix", "uat"); got != "v1.4.2-hotfix.2.uat.01" {
+		t.Errorf("preview hotfix uat got %q", got)
+	}
+	// the wrapping sources parse with the same qualifiers
+	if got := run("preview", "--cache", "--verbose", "hotfix"); got != "v1.4.2-hotfix.2" {
+		t.Errorf("preview --cache --verbose hotfix got %q", got)
+	}
+	if got := run("calc", "--config", filepath.Join(dir, gittaginc.ConfigFile), "v1.4.2-hotfix.2.uat.01", "patch"); got != "v1.4.2-hotfix.2" {
+		t.Errorf("calc patch got %q", got)
+	}
+	if got := run(completeCommand, "preview", "hot"); got != "hotfix" {
+		t.Errorf("completion got %q", got)
+	}
+}
+
+func TestMain_Line(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.2.3", c1, false)
+	testTag(t, r, "v1.5.0", c1, false)
+	testCommit(t, r, dir, "two")
+
+	next := func(t *testing.T, args ...string) (string, error) {
+		t.Helper()
+		cmd := exec.Command(exePath, append([]string{"preview"}, args...)...)
+		cmd.Dir = dir
+		stdout, err := cmd.Output()
+		return strings.TrimSpace(string(stdout)), err
+	}
+
+	if got, err := next(t, "patch"); err != nil || got != "v1.5.1" {
+		t.Errorf("patch got %q, %v", got, err)
+	}
+	if got, err := next(t, "--line", "1.2", "patch"); err != nil || got != "v1.2.4" {
+		t.Errorf("--line 1.2 patch got %q, %v", got, err)
+	}
+	if got, err := next(t, "--line", "1.2", "minor"); err == nil {
+		t.Errorf("minor left the line: %q", got)
+	}
+	if got, err := next(t, "--line", "1.3", "patch"); err == nil {
+		t.Errorf("empty line was bumped: %q", got)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, gittaginc.ConfigFile), []byte(`{"line_branch": "release/(\\d+)\\.(\\d+)"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("release/1.2"), Create: true, Keep: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := next(t, "patch"); err != nil || got != "v1.2.4" {
+		t.Errorf("patch on release/1.2 got %q, %v", got, err)
+	}
+	if got, err := next(t, "--line", "1.5", "patch"); err != nil || got != "v1.5.1" {
+		t.Errorf("--line overriding the branch got %q, %v", got, err)
+	}
+}
+
+func TestMain_DockerTags(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.4.1", c1, false)
+	testTag(t, r, "v2.0.0", c1, false)
+	testCommit(t, r, dir, "two")
+
+	run := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command(exePath, args...)
+		cmd.Dir = dir
+		stdout, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+		return string(stdout)
+	}
+	if got := run("preview", "--output", "docker-tags", "--line", "1.4", "patch"); got != "1.4.2\n1.4\n1\n" {
+		t.Errorf("preview got %q", got)
+	}
+	if got := run("calc", "--output", "docker-tags", "--separator", ",", "v2.0.0", "patch", "uat"); got != "2.0.1-uat01,uat\n" {
+		t.Errorf("calc got %q", got)
+	}
+}
+
+func TestVerboseSourceCache(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0", c1, true)
+	cached, err := gittaginc.NewCachedTagSource(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	defer func(w io.Writer) { out = w }(out)
+	out = &buf
+
+	var src gittaginc.TagSource = verboseSource{cached}
+	if _, ok := src.(gittaginc.ParsedTagSource); !ok {
+		t.Fatal("verboseSource hides the cache's parsed tags")
+	}
+	highest, err := gittaginc.FindHighestVersionTag(src, "auto")
+	if err != nil || highest.String() != "v1.0.0" {
+		t.Fatalf("highest got %s, %v", highest, err)
+	}
+	if h, err := gittaginc.GetHash(src, highest); err != nil || h != c1.String() {
+		t.Errorf("GetHash got %s, %v", h, err)
+	}
+	if !strings.Contains(buf.String(), "Ref: refs/tags/v1.0.0") {
+		t.Errorf("verbose output %q", buf.String())
+	}
+}
+
+func TestVerboseSourceRefNames(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0-rc-1", c1, false)
+	cached, err := gittaginc.NewCachedTagSource(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	defer func(w io.Writer) { out = w }(out)
+	out = &buf
+
+	src := verboseSource{cached}
+	if _, err := src.ParsedTags(); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != "Ref: refs/tags/v1.0.0-rc-1\n" {
+		t.Errorf("verbose output %q", got)
+	}
+	if _, err := (verboseSource{&gittaginc.MemoryTagSource{}}).IsClean(); err == nil {
+		t.Errorf("IsClean reported a source without worktree status")
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+const (
+	OutputText       = "text"
+	OutputJSON       = "json"
+	OutputDockerTags = "docker-tags"
+)
+
+// Stable error codes reported in the "errors" array of the JSON output, in
+// addition to the gittaginc.ErrCode* codes returned by gittaginc.Bump.
+const (
+	ErrCodeStdinRead          = "stdin_read_failed"
+	ErrCodeInvalidBaseVersion = "invalid_base_version"
+	ErrCodeRepositoryNotFound = "repository_not_found"
+	ErrCodeRepositoryOpen     = "repository_open_failed"
+	ErrCodeTaggerNotSet       = "tagger_not_configured"
+	ErrCodeConfig             = "config_invalid"
+	ErrCodeRelease            = "release_failed"
+	ErrCodeCIOutput           = "ci_output_failed"
+)
+
+type reportComponents struct {
+	Major        int    `json:"major"`
+	Minor        int    `json:"minor"`
+	Patch        int    `json:"patch"`
+	Stage        string `json:"stage,omitempty"`
+	StageNum     *int   `json:"stage_number,omitempty"`
+	Qualifier    string `json:"qualifier,omitempty"`
+	QualifierNum *int   `json:"qualifier_number,omitempty"`
+	Env          string `json:"env,omitempty"`
+	EnvNum       *int   `json:"env_number,omitempty"`
+	Release      *int   `json:"release,omitempty"`
+}
+
+type reportError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// runReport is the single JSON document written to stdout by --output json.
+type runReport struct {
+	Previous   string            `json:"previous"`
+	Tag        string            `json:"tag"`
+	Components *reportComponents `json:"components"`
+	Mode       string            `json:"mode"`
+	Target     string            `json:"target"`
+	DryRun     bool              `json:"dry_run"`
+	Pushed     bool              `json:"pushed"` // by --release
+	Signed     bool              `json:"signed"` // tags are never signed
+	ReleaseURL string            `json:"release_url,omitempty"`
+	Webhooks   []reportWebhook   `json:"webhooks,omitempty"`
+	Errors     []reportError     `json:"errors"`
+}
+
+// report is nil unless --output json was requested.
+var report *runReport
+
+func newComponents(t *gittaginc.Tag) *reportComponents {
+	if t == nil {
+		return nil
+	}
+	c := &reportComponents{
+		Major:        t.Major,
+		Minor:        t.Minor,
+		Patch:        t.Patch,
+		Stage:        t.StageName,
+		StageNum:     t.Stage,
+		Qualifier:    t.QualifierName,
+		QualifierNum: t.Qualifier,
+		Release:      t.Release,
+	}
+	if t.Uat != nil {
+		c.Env = "uat"
+		c.EnvNum = t.Uat
+	} else if t.Test != nil {
+		c.Env = "test"
+		c.EnvNum = t.Test
+	}
+	return c
+}
+
+func (r *runReport) setPrevious(t *gittaginc.Tag) {
+	if t == nil {
+		return
+	}
+	r.Previous = t.String()
+}
+
+func (r *runReport) setTag(t *gittaginc.Tag) {
+	if t == nil {
+		return
+	}
+	r.Tag = t.String()
+	r.Mode = t.Mode
+	r.Components = newComponents(t)
+}
+
+func writeReport(w io.Writer) {
+	if report.Errors == nil {
+		report.Errors = []reportError{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		log.Printf("Failed to write JSON output: %v", err)
+	}
+}
+
+// writeDockerTags prints the container image tags for t, given the version
+// tags that already exist, separated by --separator.
+func writeDockerTags(w io.Writer, t *gittaginc.Tag, existing []*gittaginc.Tag) {
+	sep := *separator
+	if sep == "" {
+		sep = "\n"
+	}
+	fmt.Fprintln(w, strings.Join(gittaginc.DockerTags(t, existing), sep))
+}
+
+// fail reports an error under a stable code and exits. In text mode the
+// message goes to the log as before; in JSON mode it is added to the report.
+func fail(code string, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if report != nil {
+		report.Errors = append(report.Errors, reportError{Code: code, Message: msg})
+		writeReport(os.Stdout)
+	} else {
+		log.Print(msg)
+	}
+	os.Exit(1)
+}
@@ -0,0 +1,177 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// promotion is a planned move of an environment tag's commit to the next
+// environment or to release.
+type promotion struct {
+	From   *gittaginc.Tag
+	To     *gittaginc.Tag
+	Commit plumbing.Hash
+}
+
+// PlanPromotion works out which tag to create for promote. The source is the
+// named target tag, or the highest environment tag when target is empty, in
+// which case HEAD must still be on the source's commit. Unless
+// --allow-backwards is set, a uat tag is not created below an existing uat
+// tag of the same version.
+func PlanPromotion(r *git.Repository, target, to string) (*promotion, error) {
+	var from *gittaginc.Tag
+	var commit plumbing.Hash
+	if target != "" {
+		from = parser.ParseTag(target)
+		if from == nil {
+			return nil, fmt.Errorf("%s is not a version tag", target)
+		}
+		ref, err := r.Tag(target)
+		if err != nil {
+			return nil, fmt.Errorf("looking up %s: %w", target, err)
+		}
+		if *mode != "auto" {
+			from.Mode = *mode
+		}
+		from.Hash = ref.Hash().String()
+		if commit, _, err = resolveTagRef(r, ref); err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", target, err)
+		}
+	} else {
+		var fromRef *plumbing.Reference
+		if err := ForEachTagRef(r, func(ref *plumbing.Reference, t *gittaginc.Tag) error {
+			if t == nil || (t.Test == nil && t.Uat == nil) {
+				return nil
+			}
+			if from == nil || from.LessThan(t) {
+				from = t
+				fromRef = ref
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		if from == nil {
+			return nil, errors.New("no environment tags to promote")
+		}
+		var err error
+		if commit, _, err = resolveTagRef(r, fromRef); err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", from, err)
+		}
+		head, err := r.Head()
+		if err != nil {
+			return nil, err
+		}
+		if head.Hash() != commit {
+			return nil, fmt.Errorf("HEAD (%s) has moved on from %s (%s); use --target %s to promote it anyway", head.Hash(), from, commit, from)
+		}
+	}
+
+	next := from.Clone()
+	if err := next.Promote(to); err != nil {
+		return nil, err
+	}
+	if _, err := r.Tag(next.String()); err == nil {
+		return nil, fmt.Errorf("%s already exists", next)
+	} else if !errors.Is(err, git.ErrTagNotFound) {
+		return nil, err
+	}
+	if next.Uat != nil && !*allowBackwards {
+		if err := ForEachTagRef(r, func(ref *plumbing.Reference, t *gittaginc.Tag) error {
+			if t == nil || t.Uat == nil || t.Major != next.Major || t.Minor != next.Minor || t.Patch != next.Patch {
+				return nil
+			}
+			if next.LessThan(t) {
+				return fmt.Errorf("%s is not above %s; use --allow-backwards to create it anyway: %w", next, t, gittaginc.ErrBehindTags)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+	}
+	return &promotion{From: from, To: next, Commit: commit}, nil
+}
+
+type promoteFlags struct {
+	target *string
+}
+
+// newPromoteFlags defines the flags of the promote subcommand.
+func newPromoteFlags() (*flag.FlagSet, *promoteFlags) {
+	fs := newFlagSet("promote")
+	f := &promoteFlags{
+		target: fs.String("target", "", "Promote this tag instead of the highest environment tag, even if HEAD has moved"),
+	}
+	fs.BoolVar(dry, "dry", *dry, "Dry run")
+	shareFlags(fs, "allow-backwards", "require-sign-off", "config", "release", "release-api", "ci", "output-file")
+	return fs, f
+}
+
+func runPromote(args []string) {
+	fs, f := newPromoteFlags()
+	_ = fs.Parse(args)
+
+	rest := fs.Args()
+	if len(rest) > 0 && rest[0] == "to" {
+		rest = rest[1:]
+	}
+	if len(rest) > 1 {
+		fmt.Fprintf(out, "Usage: promote [--target <tag>] [to <uat|release>]\n")
+		os.Exit(1)
+	}
+	to := ""
+	if len(rest) == 1 {
+		to = rest[0]
+	}
+
+	r := openRepository()
+	cfg := repoConfig
+	p, err := PlanPromotion(r, *f.target, to)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		os.Exit(1)
+	}
+	src := newTagSource(r)
+	if *requireSignOff || cfg.RequireSignOff {
+		if err := gittaginc.CheckSignOff(src, p.To, p.Commit.String()); err != nil {
+			fmt.Fprintf(out, "%v\n", err)
+			os.Exit(1)
+		}
+	}
+	fmt.Fprintf(out, "Promoting %s (%s)\n", p.From, p.Commit)
+	fmt.Fprintf(out, "Creating %s\n", p.To)
+	res := gittaginc.BumpResult{Previous: p.From, Tag: p.To, Target: p.Commit.String()}
+	if *dry {
+		writeCIOutputs(p.From.String(), p.To, res.Target, false)
+		fmt.Fprintf(out, "Dry run finished.\n")
+		return
+	}
+	tagger := loadTagger(r)
+	var releases *gittaginc.ReleaseClient
+	if *createRelease {
+		releases = newReleaseClient(r, cfg)
+	}
+	webhooks := loadWebhooks(cfg)
+	if err := src.CreateTag(p.To.String(), res.Target, &gittaginc.CreateTagOptions{
+		Message: p.To.String(),
+		Tagger:  tagger,
+	}); err != nil {
+		fmt.Fprintf(out, "Failed to create tag: %v\n", err)
+		os.Exit(1)
+	}
+	res.Created = true
+	writeCIOutputs(p.From.String(), p.To, res.Target, true)
+	tagCreated(r, res, tagger, webhooks, releases)
+}
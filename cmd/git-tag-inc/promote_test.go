@@ -0,0 +1,146 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+func TestPlanPromotion(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0-test.02", c1, true)
+	testTag(t, r, "v1.0.0-test.01", c1, false)
+
+	p, err := PlanPromotion(r, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.From.String() != "v1.0.0-test.02" || p.To.String() != "v1.0.0-uat.02" || p.Commit != c1 {
+		t.Errorf("unexpected promotion %s -> %s on %s", p.From, p.To, p.Commit)
+	}
+
+	testTag(t, r, "v1.0.0-uat.02", c1, true)
+	if _, err := PlanPromotion(r, "v1.0.0-test.01", ""); !errors.Is(err, gittaginc.ErrBehindTags) {
+		t.Errorf("expected ErrBehindTags promoting below uat.02, got %v", err)
+	}
+	defer func(v bool) { *allowBackwards = v }(*allowBackwards)
+	*allowBackwards = true
+	if p, err = PlanPromotion(r, "v1.0.0-test.01", ""); err != nil || p.To.String() != "v1.0.0-uat.01" {
+		t.Errorf("--allow-backwards promotion got %v, %v", p, err)
+	}
+	*allowBackwards = false
+
+	p, err = PlanPromotion(r, "", "release")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.From.String() != "v1.0.0-uat.02" || p.To.String() != "v1.0.0" {
+		t.Errorf("unexpected promotion %s -> %s", p.From, p.To)
+	}
+
+	if _, err := PlanPromotion(r, "", "uat"); err == nil {
+		t.Errorf("expected error promoting uat to uat")
+	}
+
+	testCommit(t, r, dir, "two")
+	if _, err := PlanPromotion(r, "", ""); err == nil {
+		t.Errorf("expected error when HEAD has moved")
+	}
+	p, err = PlanPromotion(r, "v1.0.0-uat.02", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.To.String() != "v1.0.0" || p.Commit != c1 {
+		t.Errorf("unexpected targeted promotion %s on %s", p.To, p.Commit)
+	}
+
+	testTag(t, r, "v1.0.0", c1, false)
+	if _, err := PlanPromotion(r, "v1.0.0-uat.02", ""); err == nil {
+		t.Errorf("expected error when the promoted tag exists")
+	}
+}
+
+func TestMain_PromoteSignOff(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0-test.01", c1, false)
+	setTestUser(t, r)
+	if err := os.WriteFile(filepath.Join(dir, gittaginc.ConfigFile), []byte(`{"require_sign_off": true}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	promote := func(args ...string) error {
+		cmd := exec.Command(exePath, append([]string{"promote"}, args...)...)
+		cmd.Dir = dir
+		return cmd.Run()
+	}
+	if err := promote("to", "release"); err == nil {
+		t.Errorf("release promoted without a uat tag")
+	}
+	if _, err := r.Tag("v1.0.0"); err == nil {
+		t.Errorf("release tag created without sign-off")
+	}
+	if err := promote(); err != nil {
+		t.Fatalf("promote to uat: %v", err)
+	}
+	if err := promote("to", "release"); err != nil {
+		t.Errorf("promote to release after uat: %v", err)
+	}
+	if _, err := r.Tag("v1.0.0"); err != nil {
+		t.Errorf("release tag missing: %v", err)
+	}
+}
+
+func TestMain_PromoteNotifies(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.0.0-test.01", c1, false)
+	setTestUser(t, r)
+	var got []gittaginc.WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var p gittaginc.WebhookPayload
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			t.Error(err)
+		}
+		got = append(got, p)
+	}))
+	defer srv.Close()
+	settings := `{"webhooks": [{"url": "` + srv.URL + `", "environments": ["uat"]}]}`
+	if err := os.WriteFile(filepath.Join(dir, gittaginc.ConfigFile), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs := filepath.Join(t.TempDir(), "outputs")
+	cmd := exec.Command(exePath, "promote", "--output-file", outputs)
+	cmd.Dir = dir
+	if b, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("%v: %s", err, b)
+	}
+	if len(got) != 1 || got[0].Tag != "v1.0.0-uat.01" || got[0].Previous != "v1.0.0-test.01" || got[0].Commit != c1.String() {
+		t.Errorf("unexpected payloads %+v", got)
+	}
+	b, err := os.ReadFile(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "VERSION=v1.0.0-uat.01\n") {
+		t.Errorf("unexpected outputs %q", b)
+	}
+}
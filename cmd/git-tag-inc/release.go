@@ -0,0 +1,181 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+)
+
+// releaseTimeout bounds the call creating a release.
+const releaseTimeout = 30 * time.Second
+
+// newReleaseClient works out where --release creates releases, failing
+// before anything is tagged when the repository or the token is unknown.
+func newReleaseClient(r *git.Repository, cfg *gittaginc.Config) *gittaginc.ReleaseClient {
+	rc := gittaginc.ReleaseConfig{}
+	if cfg.Release != nil {
+		rc = *cfg.Release
+	}
+	c := &gittaginc.ReleaseClient{BaseURL: rc.APIURL, Owner: rc.Owner, Repo: rc.Repo}
+	if *releaseAPI != "" {
+		c.BaseURL = *releaseAPI
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = gittaginc.DefaultReleaseAPI
+	}
+	if c.Owner == "" || c.Repo == "" {
+		owner, repo, err := originRepo(r)
+		if err != nil {
+			fail(ErrCodeRelease, "--release needs the repository: %v; set release.owner and release.repo in %s", err, gittaginc.ConfigFile)
+		}
+		if c.Owner == "" {
+			c.Owner = owner
+		}
+		if c.Repo == "" {
+			c.Repo = repo
+		}
+	}
+	tokenEnv := rc.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = gittaginc.DefaultReleaseTokenEnv
+	}
+	if c.Token = os.Getenv(tokenEnv); c.Token == "" {
+		fail(ErrCodeRelease, "--release needs an API token in $%s", tokenEnv)
+	}
+	return c
+}
+
+// originRepo returns the owner and repository of the origin remote's URL.
+func originRepo(r *git.Repository) (string, string, error) {
+	remote, err := r.Remote("origin")
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return "", "", errors.New("there is no origin remote")
+	} else if err != nil {
+		return "", "", err
+	}
+	for _, u := range remote.Config().URLs {
+		if owner, repo, ok := gittaginc.ParseRemoteRepo(u); ok {
+			return owner, repo, nil
+		}
+	}
+	return "", "", fmt.Errorf("cannot tell the repository from origin %v", remote.Config().URLs)
+}
+
+// commitSubjects lists the commits reachable from target but not from the
+// previous tag, newest first, as a short hash and subject.
+func commitSubjects(r *git.Repository, previous *gittaginc.Tag, target string) ([]string, error) {
+	seen := map[plumbing.Hash]bool{}
+	if previous != nil && previous.Hash != "" {
+		from, err := gittaginc.PeelToCommit(r, plumbing.NewHash(previous.Hash))
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", previous, err)
+		}
+		iter, err := r.Log(&git.LogOptions{From: from})
+		if err != nil {
+			return nil, err
+		}
+		err = iter.ForEach(func(c *object.Commit) error {
+			seen[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	iter, err := r.Log(&git.LogOptions{From: plumbing.NewHash(target)})
+	if err != nil {
+		return nil, err
+	}
+	var subjects []string
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !seen[c.Hash] {
+			subjects = append(subjects, shortHash(c.Hash.String())+" "+firstLine(c.Message))
+		}
+		return nil
+	})
+	return subjects, err
+}
+
+// pushAuth returns the credentials to push to origin with. go-git does not
+// use git's credential helpers, so https remotes without credentials of their
+// own get the API token, the way GitHub and Gitea accept it. Other remotes
+// use go-git's defaults, such as the ssh agent.
+func pushAuth(r *git.Repository, token string) (transport.AuthMethod, error) {
+	remote, err := r.Remote("origin")
+	if err != nil {
+		return nil, err
+	}
+	urls := remote.Config().URLs
+	if len(urls) == 0 || token == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(urls[0])
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.User != nil {
+		return nil, nil
+	}
+	return &http.BasicAuth{Username: "x-access-token", Password: token}, nil
+}
+
+// pushTag pushes the tag to origin. A release names its tag, and the API
+// creates one on the target commit when the remote does not have it, which
+// would then differ from the local tag.
+func pushTag(r *git.Repository, tag, token string) error {
+	auth, err := pushAuth(r, token)
+	if err != nil {
+		return err
+	}
+	ref := plumbing.NewTagReferenceName(tag)
+	err = r.Push(&git.PushOptions{
+		RemoteName: "origin",
+		RefSpecs:   []config.RefSpec{config.RefSpec(ref + ":" + ref)},
+		Auth:       auth,
+	})
+	if errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return nil
+	}
+	return err
+}
+
+// publishRelease pushes the tag Bump just created to origin and creates its
+// release. The tag is kept when this fails.
+func publishRelease(r *git.Repository, c *gittaginc.ReleaseClient, res gittaginc.BumpResult) {
+	subjects, err := commitSubjects(r, res.Previous, res.Target)
+	if err != nil {
+		fail(ErrCodeRelease, "Created %s but not its release, listing commits failed: %v", res.Tag, err)
+	}
+	if err := pushTag(r, res.Tag.String(), c.Token); err != nil {
+		fail(ErrCodeRelease, "Created %s but not its release, pushing it to origin failed: %v", res.Tag, err)
+	}
+	fmt.Fprintf(out, "Pushed %s to origin\n", res.Tag)
+	if report != nil {
+		report.Pushed = true
+	}
+	req := gittaginc.NewReleaseRequest(res.Tag, res.Target, gittaginc.ReleaseNotes(res.Previous, res.Tag, subjects))
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	rel, err := c.Create(ctx, req)
+	if err != nil {
+		fail(ErrCodeRelease, "Created %s but not its release: %v", res.Tag, err)
+	}
+	fmt.Fprintf(out, "Created release %s %s\n", res.Tag, rel.HTMLURL)
+	if report != nil {
+		report.ReleaseURL = rel.HTMLURL
+	}
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// shownTag is the output of show.
+type shownTag struct {
+	listEntry
+	Tagger     string            `json:"tagger,omitempty"`
+	Message    string            `json:"message,omitempty"`
+	Components *reportComponents `json:"components,omitempty"`
+}
+
+// ShowTag describes the named tag, or the highest version tag when name is
+// empty.
+func ShowTag(r *git.Repository, name string) (*shownTag, error) {
+	if name == "" {
+		highest, err := gittaginc.FindHighestVersionTag(newTagSource(r), *mode)
+		if err != nil {
+			return nil, err
+		}
+		if highest.Hash == "" {
+			return nil, fmt.Errorf("there are no version tags")
+		}
+		if name, err = tagRefName(newTagSource(r), highest); err != nil {
+			return nil, err
+		}
+	}
+	ref, err := r.Tag(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	commit, to, err := resolveTagRef(r, ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", name, err)
+	}
+	t := parser.ParseTag(name)
+	s := &shownTag{
+		listEntry:  listEntry{Name: name, Tag: t, Valid: t != nil, Commit: commit.String()},
+		Components: newComponents(t),
+	}
+	if t != nil {
+		if *mode != "auto" {
+			t.Mode = *mode
+		}
+		s.Mode = t.Mode
+	}
+	if to != nil {
+		s.Annotated = true
+		when := to.Tagger.When
+		s.Date = &when
+		s.Tagger = fmt.Sprintf("%s <%s>", to.Tagger.Name, to.Tagger.Email)
+		s.Message = strings.TrimSpace(to.Message)
+	}
+	return s, nil
+}
+
+// tagRefName returns the name of the ref t was listed with, which differs
+// from t.String() when the name is not in canonical form or --mode is given.
+func tagRefName(src gittaginc.TagSource, t *gittaginc.Tag) (string, error) {
+	refs, err := src.Tags()
+	if err != nil {
+		return "", err
+	}
+	p := gittaginc.SourceParser(src)
+	for _, ref := range refs {
+		if ref.Hash != t.Hash {
+			continue
+		}
+		if listed := p.ParseTag(ref.Name); listed != nil {
+			listed.Mode = t.Mode
+			if listed.String() == t.String() {
+				return ref.Name, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%s: %w", t, gittaginc.ErrTagNotFound)
+}
+
+func writeShow(w io.Writer, s *shownTag) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', 0)
+	fmt.Fprintf(tw, "Tag:\t%s\n", s.Name)
+	if !s.Valid {
+		fmt.Fprintf(tw, "Version:\tnot a version tag\n")
+	} else {
+		c := s.Components
+		fmt.Fprintf(tw, "Mode:\t%s\n", s.Mode)
+		fmt.Fprintf(tw, "Version:\t%d.%d.%d\n", c.Major, c.Minor, c.Patch)
+		if c.Stage != "" {
+			fmt.Fprintf(tw, "Stage:\t%s %s\n", c.Stage, optionalInt(c.StageNum))
+		}
+		if c.Qualifier != "" {
+			fmt.Fprintf(tw, "Qualifier:\t%s %s\n", c.Qualifier, optionalInt(c.QualifierNum))
+		}
+		if c.Env != "" {
+			fmt.Fprintf(tw, "Environment:\t%s %s\n", c.Env, optionalInt(c.EnvNum))
+		}
+		if c.Release != nil {
+			fmt.Fprintf(tw, "Release:\t%d\n", *c.Release)
+		}
+	}
+	fmt.Fprintf(tw, "Commit:\t%s\n", s.Commit)
+	if s.Annotated {
+		fmt.Fprintf(tw, "Tagger:\t%s\n", s.Tagger)
+		fmt.Fprintf(tw, "Date:\t%s\n", s.Date.Format(time.RFC3339))
+		fmt.Fprintf(tw, "Message:\t%s\n", s.Message)
+	} else {
+		fmt.Fprintf(tw, "Annotated:\tno\n")
+	}
+	return tw.Flush()
+}
+
+func optionalInt(n *int) string {
+	if n == nil {
+		return ""
+	}
+	return fmt.Sprint(*n)
+}
+
+// newShowFlags defines the flags of the show subcommand.
+func newShowFlags() *flag.FlagSet {
+	fs := newFlagSet("show")
+	fs.StringVar(output, "output", *output, "Output format: text or json")
+	return fs
+}
+
+func runShow(args []string) {
+	fs := newShowFlags()
+	_ = fs.Parse(args)
+	if fs.NArg() > 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	r := openRepository()
+	s, err := ShowTag(r, strings.TrimPrefix(fs.Arg(0), plumbing.NewTagReferenceName("").String()))
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		os.Exit(1)
+	}
+	if *output == OutputJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(s)
+	} else {
+		err = writeShow(os.Stdout, s)
+	}
+	if err != nil {
+		fmt.Fprintf(out, "Failed to write %s: %v\n", s.Name, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,35 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/arran4/git-tag-inc"
+)
+
+func TestShowTagNonCanonical(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.2.2", c1, false)
+	c2 := testCommit(t, r, dir, "two")
+	testTag(t, r, "v1.2.3-rc-1", c2, true)
+
+	for _, m := range []string{"auto", gittaginc.ModeLegacy, gittaginc.ModeSemver} {
+		t.Run(m, func(t *testing.T) {
+			defer func(old string) { *mode = old }(*mode)
+			*mode = m
+			s, err := ShowTag(r, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s.Name != "v1.2.3-rc-1" || s.Commit != c2.String() || !s.Annotated {
+				t.Errorf("got %+v", s)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+var (
+	ErrNothingToUndo = errors.New("no tags created by git-tag-inc are recorded in the journal")
+	ErrTagOnRemote   = errors.New("tag exists on the remote; use --force to delete it locally anyway")
+)
+
+// UndoLastTag deletes the most recent tag recorded in the journal. The tag
+// must still point at the recorded commit and, unless force is set, must not
+// be present on the named remote. With dry set nothing is changed.
+func UndoLastTag(r *git.Repository, remoteName string, force, dry bool) (*journalEntry, error) {
+	entries, err := readJournal(r)
+	if err != nil {
+		return nil, fmt.Errorf("reading journal: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, ErrNothingToUndo
+	}
+	last := entries[len(entries)-1]
+
+	ref, err := r.Tag(last.Tag)
+	if errors.Is(err, git.ErrTagNotFound) {
+		if !force || dry {
+			return &last, fmt.Errorf("tag %s no longer exists; use --force to drop it from the journal", last.Tag)
+		}
+		if err := writeJournal(r, entries[:len(entries)-1]); err != nil {
+			return nil, fmt.Errorf("updating journal: %w", err)
+		}
+		return &last, fmt.Errorf("tag %s no longer exists; dropped it from the journal", last.Tag)
+	} else if err != nil {
+		return nil, err
+	}
+	commit, _, err := resolveTagRef(r, ref)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", last.Tag, err)
+	}
+	if commit.String() != last.Commit {
+		return &last, fmt.Errorf("tag %s points at %s but was created on %s; refusing to delete it", last.Tag, commit, last.Commit)
+	}
+
+	if !force && remoteName != "" {
+		onRemote, err := tagOnRemote(r, remoteName, last.Tag)
+		if err != nil {
+			return &last, fmt.Errorf("checking remote %s: %w; use --force to skip the check", remoteName, err)
+		}
+		if onRemote {
+			return &last, fmt.Errorf("%s on %s: %w", last.Tag, remoteName, ErrTagOnRemote)
+		}
+	}
+
+	if dry {
+		return &last, nil
+	}
+	if err := r.DeleteTag(last.Tag); err != nil {
+		return &last, fmt.Errorf("deleting %s: %w", last.Tag, err)
+	}
+	if err := writeJournal(r, entries[:len(entries)-1]); err != nil {
+		return &last, fmt.Errorf("updating journal: %w", err)
+	}
+	return &last, nil
+}
+
+// tagOnRemote reports whether the remote advertises the tag. Remotes that are
+// not configured are treated as not having it.
+func tagOnRemote(r *git.Repository, remoteName, tag string) (bool, error) {
+	remote, err := r.Remote(remoteName)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	refs, err := remote.List(&git.ListOptions{})
+	if errors.Is(err, transport.ErrEmptyRemoteRepository) {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	name := plumbing.NewTagReferenceName(tag)
+	for _, ref := range refs {
+		if ref.Name() == name {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+type undoFlags struct {
+	remote *string
+}
+
+// newUndoFlags defines the flags of the undo subcommand.
+func newUndoFlags() (*flag.FlagSet, *undoFlags) {
+	fs := newFlagSet("undo")
+	f := &undoFlags{
+		remote: fs.String("remote", "origin", "Remote to check for the tag before deleting it"),
+	}
+	fs.BoolVar(force, "force", *force, "Delete the tag even if it exists on the remote")
+	fs.BoolVar(dry, "dry", *dry, "Dry run")
+	return fs, f
+}
+
+func runUndo(args []string) {
+	fs, f := newUndoFlags()
+	_ = fs.Parse(args)
+
+	r := openRepository()
+	e, err := UndoLastTag(r, *f.remote, *force, *dry)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		os.Exit(1)
+	}
+	if *dry {
+		fmt.Fprintf(out, "Would delete %s (%s)\n", e.Tag, e.Commit)
+		return
+	}
+	fmt.Fprintf(out, "Deleted %s (%s)\n", e.Tag, e.Commit)
+}
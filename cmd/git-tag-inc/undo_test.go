@@ -0,0 +1,102 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+)
+
+func TestUndoLastTag(t *testing.T) {
+	r, dir := newTestRepo(t)
+	c1 := testCommit(t, r, dir, "one")
+	c2 := testCommit(t, r, dir, "two")
+
+	if _, err := UndoLastTag(r, "origin", false, false); !errors.Is(err, ErrNothingToUndo) {
+		t.Fatalf("expected ErrNothingToUndo, got %v", err)
+	}
+
+	record := func(tag, commit string) {
+		t.Helper()
+		if err := appendJournal(r, journalEntry{Tag: tag, Commit: commit, Created: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testTag(t, r, "v0.0.1", c1, true)
+	record("v0.0.1", c1.String())
+	testTag(t, r, "v0.0.2", c2, false)
+	record("v0.0.2", c2.String())
+
+	t.Run("dry run keeps the tag", func(t *testing.T) {
+		if _, err := UndoLastTag(r, "origin", false, true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.Tag("v0.0.2"); err != nil {
+			t.Fatalf("dry run removed tag: %v", err)
+		}
+	})
+
+	t.Run("deletes most recent", func(t *testing.T) {
+		e, err := UndoLastTag(r, "origin", false, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if e.Tag != "v0.0.2" {
+			t.Errorf("undid %s", e.Tag)
+		}
+		if _, err := r.Tag("v0.0.2"); !errors.Is(err, git.ErrTagNotFound) {
+			t.Errorf("expected tag to be deleted, got %v", err)
+		}
+		entries, err := readJournal(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 || entries[0].Tag != "v0.0.1" {
+			t.Errorf("unexpected journal %#v", entries)
+		}
+	})
+
+	t.Run("refuses moved tag", func(t *testing.T) {
+		record("v0.0.1", c2.String())
+		if _, err := UndoLastTag(r, "origin", true, false); err == nil {
+			t.Fatalf("expected error for tag pointing elsewhere")
+		}
+		entries, _ := readJournal(r)
+		if err := writeJournal(r, entries[:len(entries)-1]); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("refuses pushed tag", func(t *testing.T) {
+		remoteDir := t.TempDir()
+		if _, err := git.PlainInit(remoteDir, true); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := r.CreateRemote(&config.RemoteConfig{Name: "origin", URLs: []string{remoteDir}}); err != nil {
+			t.Fatal(err)
+		}
+		if err := r.Push(&git.PushOptions{
+			RemoteName: "origin",
+			RefSpecs:   []config.RefSpec{"refs/heads/master:refs/heads/master", "refs/tags/*:refs/tags/*"},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := UndoLastTag(r, "origin", false, false); !errors.Is(err, ErrTagOnRemote) {
+			t.Fatalf("expected ErrTagOnRemote, got %v", err)
+		}
+		if _, err := UndoLastTag(r, "origin", true, false); err != nil {
+			t.Fatalf("force undo failed: %v", err)
+		}
+		if _, err := r.Tag("v0.0.1"); !errors.Is(err, git.ErrTagNotFound) {
+			t.Errorf("expected tag to be deleted, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,209 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// sourceTags is the --source value that takes the version from the tags.
+const sourceTags = "tags"
+
+// sourceFilePrefix starts a --source value naming a version file.
+const sourceFilePrefix = "file:"
+
+// versionFileSource returns the path given with --source file:<path>, or ""
+// when the version comes from the tags.
+func versionFileSource() string {
+	switch {
+	case *source == sourceTags || *source == "":
+		return ""
+	case strings.HasPrefix(*source, sourceFilePrefix) && len(*source) > len(sourceFilePrefix):
+		return strings.TrimPrefix(*source, sourceFilePrefix)
+	}
+	fail(gittaginc.ErrCodeInvalidArguments, "Unknown source %q, expected %s or %s<path>", *source, sourceTags, sourceFilePrefix)
+	return ""
+}
+
+// bumpVersionFile increments the version in the file at path, relative to
+// the top of the worktree, checks it against the file and the existing
+// tags, and rewrites the file, committing it with --commit and tagging the
+// commit with --tag, which also notifies webhooks and creates the release.
+// Stage transitions and --require-sign-off apply as they do to tags.
+func bumpVersionFile(r *git.Repository, src gittaginc.TagSource, path string, cmds []string, cfg *gittaginc.Config, tagger *gittaginc.Signature, releases *gittaginc.ReleaseClient, webhooks []webhookTarget) {
+	wt, err := r.Worktree()
+	if err != nil {
+		fail(gittaginc.ErrCodeWorktree, "--source file needs a worktree: %v", err)
+	}
+	root := wt.Filesystem.Root()
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(root, path)
+	}
+	rel, err := filepath.Rel(root, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		fail(gittaginc.ErrCodeInvalidArguments, "%s is outside the worktree %s", path, root)
+	}
+
+	if !*ignore {
+		status, err := wt.Status()
+		if err != nil {
+			fail(gittaginc.ErrCodeWorktree, "Failed to get worktree status: %v", err)
+		}
+		if !status.IsClean() {
+			fail(gittaginc.ErrCodeUncommitted, "there are uncommitted changes in this repo")
+		}
+	}
+	if *commitFile || *tagFile {
+		// the commit takes the whole index, so it must hold nothing else
+		status, err := wt.Status()
+		if err != nil {
+			fail(gittaginc.ErrCodeWorktree, "Failed to get worktree status: %v", err)
+		}
+		for name, st := range status {
+			if name != filepath.ToSlash(rel) && st.Staging != git.Unmodified && st.Staging != git.Untracked {
+				fail(gittaginc.ErrCodeUncommitted, "%s is staged and would be committed along with %s; unstage it first", name, rel)
+			}
+		}
+	}
+
+	current, prefixed, err := parser.ReadVersionFile(path)
+	if err != nil {
+		fail(ErrCodeInvalidBaseVersion, "Failed to read version file: %v", err)
+	}
+	if *mode != "auto" {
+		current.Mode = *mode
+	}
+	next := current.Clone()
+	err = next.IncrementWithOptions(parser.CommandsToFlags(cmds, *mode), gittaginc.IncrementOptions{
+		AllowBackwards: *allowBackwards,
+		SkipForwards:   *skipForwards,
+		Transitions:    cfg.Transitions,
+		Force:          *force,
+		Qualifiers:     parser.Qualifiers(),
+		Explain:        explainWriter(),
+	})
+	if report != nil {
+		report.setPrevious(current)
+		report.setTag(next)
+	}
+	if err != nil {
+		fail(gittaginc.ErrCodeIncrement, "%v", err)
+	}
+	fmt.Fprintf(out, "Current: %s (%s)\n", current, rel)
+
+	head, err := src.Head()
+	if err != nil {
+		fail(gittaginc.ErrCodeHead, "failed to get current hash: %v", err)
+	}
+	if report != nil {
+		report.Target = head
+	}
+	if !*repeating {
+		// both the tag of the file's version and the last tag of the same
+		// kind must be on an older commit
+		flags := parser.CommandsToFlags(cmds, *mode)
+		lastSimilar, err := gittaginc.FindHighestSimilarVersionTag(src, *mode, flags.Env)
+		if err != nil {
+			fail(gittaginc.ErrCodeTagLookup, "failed to find highest similar version tag: %v", err)
+		}
+		for _, previous := range []*gittaginc.Tag{current, lastSimilar} {
+			h, err := gittaginc.GetHash(src, previous)
+			if err != nil {
+				fail(gittaginc.ErrCodeTagLookup, "failed to get hash for %s: %v", previous, err)
+			}
+			if h != "" && h == head {
+				fail(gittaginc.ErrCodeRepeatedHash, "Hash is the same for this and previous tag: (%s) %s and %s", previous, h, head)
+			}
+		}
+	}
+	if err := gittaginc.CheckVersionAgainstTags(src, next, *mode, *allowBackwards); err != nil {
+		code := gittaginc.ErrCodeIncrement
+		if errors.Is(err, gittaginc.ErrTagExists) {
+			code = gittaginc.ErrCodeTagCreate
+		}
+		fail(code, "%v", err)
+	}
+	if *requireSignOff || cfg.RequireSignOff {
+		// the release commit only changes the file, so the earlier
+		// environment's tag must be on the commit it is made from
+		if err := gittaginc.CheckSignOff(src, next, head); err != nil {
+			fail(gittaginc.ErrCodeSignOff, "%v", err)
+		}
+	}
+
+	fmt.Fprintf(out, "Creating %s\n", next)
+	res := gittaginc.BumpResult{Previous: current, Tag: next, Target: head}
+	if h, err := src.ResolveTag(current.String()); err == nil {
+		// lets the release notes start from the current version's tag
+		res.Previous.Hash = h
+	}
+	if *dry {
+		if !*printVersionOnly {
+			fmt.Fprintf(out, "Dry run finished.\n")
+		}
+		finishVersionFile(src, res)
+		return
+	}
+
+	if err := gittaginc.WriteVersionFile(path, next, prefixed); err != nil {
+		fail(gittaginc.ErrCodeTagCreate, "Failed to write version file: %v", err)
+	}
+	fmt.Fprintf(out, "Wrote %s to %s\n", next, rel)
+	if *commitFile || *tagFile {
+		if tagger == nil {
+			fail(ErrCodeTaggerNotSet, "git user.name or user.email not configured")
+		}
+		if _, err := wt.Add(filepath.ToSlash(rel)); err != nil {
+			fail(gittaginc.ErrCodeTagCreate, "Failed to stage %s: %v", rel, err)
+		}
+		sig := &object.Signature{Name: tagger.Name, Email: tagger.Email, When: tagger.When}
+		h, err := wt.Commit("Release "+next.String(), &git.CommitOptions{Author: sig})
+		if err != nil {
+			fail(gittaginc.ErrCodeTagCreate, "Failed to commit %s: %v", rel, err)
+		}
+		res.Target = h.String()
+		if report != nil {
+			report.Target = res.Target
+		}
+		fmt.Fprintf(out, "Committed %s\n", shortHash(res.Target))
+	}
+	if *tagFile {
+		opts := &gittaginc.CreateTagOptions{Message: next.String(), Tagger: tagger}
+		if err := src.CreateTag(next.String(), res.Target, opts); err != nil {
+			fail(gittaginc.ErrCodeTagCreate, "failed to create tag: %v", err)
+		}
+		res.Created = true
+		fmt.Fprintf(out, "Tagged %s\n", next)
+		tagCreated(r, res, tagger, webhooks, releases)
+	}
+	finishVersionFile(src, res)
+}
+
+// finishVersionFile writes the outputs of a --source file run.
+func finishVersionFile(src gittaginc.TagSource, res gittaginc.BumpResult) {
+	writeCIOutputs(res.Previous.String(), res.Tag, res.Target, res.Created)
+	switch {
+	case report != nil:
+		writeReport(os.Stdout)
+	case *output == OutputDockerTags:
+		existing, err := gittaginc.VersionTags(src, *mode)
+		if err != nil {
+			fail(gittaginc.ErrCodeTagLookup, "Failed to list tags: %v", err)
+		}
+		writeDockerTags(os.Stdout, res.Tag, existing)
+	case *printVersionOnly:
+		fmt.Println(res.Tag.String())
+	}
+}
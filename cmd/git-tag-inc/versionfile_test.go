@@ -0,0 +1,174 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestMain_VersionFile(t *testing.T) {
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	versionFile := filepath.Join(dir, "VERSION")
+	if err := os.WriteFile(versionFile, []byte("1.4.2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c1 := testCommit(t, r, dir, "one")
+	testTag(t, r, "v1.4.2", c1, false)
+	testCommit(t, r, dir, "two")
+	setTestUser(t, r)
+
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(exePath, append([]string{"--source", "file:VERSION"}, args...)...)
+		cmd.Dir = filepath.Join(dir)
+		stdout, err := cmd.Output()
+		return strings.TrimSpace(string(stdout)), err
+	}
+	read := func() string {
+		b, err := os.ReadFile(versionFile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	if got, err := run("--print-version-only", "minor"); err != nil || got != "v1.5.0" || read() != "1.4.2\n" {
+		t.Errorf("next got %q, %v, file %q", got, err, read())
+	}
+	if _, err := run("patch"); err != nil || read() != "1.4.3\n" {
+		t.Errorf("patch got %v, file %q", err, read())
+	}
+	head, _ := r.Head()
+
+	// the file went backwards behind the tags
+	testTag(t, r, "v1.6.0", c1, false)
+	if _, err := run("patch"); err == nil || read() != "1.4.3\n" {
+		t.Errorf("bump behind v1.6.0 was allowed, file %q", read())
+	}
+	if _, err := run("--allow-backwards", "--tag", "patch"); err != nil || read() != "1.4.4\n" {
+		t.Fatalf("--tag patch got %v, file %q", err, read())
+	}
+	tag, err := r.Tag("v1.4.4")
+	if err != nil {
+		t.Fatalf("not tagged: %v", err)
+	}
+	to, err := r.TagObject(tag.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := r.CommitObject(to.Target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Message != "Release v1.4.4" || len(c.ParentHashes) != 1 || c.ParentHashes[0] != head.Hash() {
+		t.Errorf("unexpected commit %q with parents %v", c.Message, c.ParentHashes)
+	}
+	if f, err := c.File("VERSION"); err != nil {
+		t.Error(err)
+	} else if content, _ := f.Contents(); content != "1.4.4\n" {
+		t.Errorf("committed %q", content)
+	}
+	newHead, _ := r.Head()
+	if newHead.Hash() != plumbing.NewHash(c.Hash.String()) {
+		t.Errorf("HEAD is %s, not the release commit %s", newHead.Hash(), c.Hash)
+	}
+
+	// nothing changed since v1.4.4 was tagged
+	if _, err := run("--allow-backwards", "--tag", "patch"); err == nil {
+		t.Errorf("repeating the release on the same commit was allowed")
+	}
+}
+
+// newVersionFileRepo returns a repository with VERSION holding version at
+// its two commits, and a function running the binary with --source
+// file:VERSION in it.
+func newVersionFileRepo(t *testing.T, version string) (*git.Repository, string, func(args ...string) (string, error)) {
+	t.Helper()
+	exePath := buildBinary(t)
+	r, dir := newTestRepo(t)
+	if err := os.WriteFile(filepath.Join(dir, "VERSION"), []byte(version+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	testCommit(t, r, dir, "one")
+	setTestUser(t, r)
+	run := func(args ...string) (string, error) {
+		cmd := exec.Command(exePath, append([]string{"--source", "file:VERSION", "--output", "json"}, args...)...)
+		cmd.Dir = dir
+		stdout, err := cmd.Output()
+		return string(stdout), err
+	}
+	return r, dir, run
+}
+
+func TestMain_VersionFileStaged(t *testing.T) {
+	r, dir, run := newVersionFileRepo(t, "1.4.2")
+	if err := os.WriteFile(filepath.Join(dir, "other.txt"), []byte("other\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	wt, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.Add("other.txt"); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := r.Head()
+	if stdout, err := run("--commit", "patch"); err == nil || !strings.Contains(stdout, gittaginc.ErrCodeUncommitted) {
+		t.Errorf("--commit with another file staged was allowed: %s", stdout)
+	}
+	if b, _ := os.ReadFile(filepath.Join(dir, "VERSION")); string(b) != "1.4.2\n" {
+		t.Errorf("VERSION rewritten to %q", b)
+	}
+	if after, _ := r.Head(); after.Hash() != before.Hash() {
+		t.Errorf("committed %s with another file staged", after.Hash())
+	}
+	// --ignore lets unstaged changes through, which the commit leaves out
+	if _, err := run("--commit", "--ignore", "patch"); err == nil {
+		t.Errorf("staged file still refused with --ignore")
+	}
+}
+
+func TestMain_VersionFileChecks(t *testing.T) {
+	r, dir, run := newVersionFileRepo(t, "1.0.0-rc.01.test.01")
+	c1, _ := r.Head()
+	testTag(t, r, "v1.0.0-rc.01.test.01", c1.Hash(), false)
+	testCommit(t, r, dir, "two")
+
+	// stage transitions apply to the file's version
+	if stdout, err := run("--dry", "beta"); err == nil || !strings.Contains(stdout, gittaginc.ErrCodeIncrement) {
+		t.Errorf("rc to beta was allowed: %s", stdout)
+	}
+	settings := `{"transitions": {"rc": ["beta"]}}`
+	if err := os.WriteFile(filepath.Join(dir, gittaginc.ConfigFile), []byte(settings), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if stdout, err := run("--dry", "beta"); err != nil {
+		t.Errorf("rc to beta refused with the configured transitions: %v %s", err, stdout)
+	}
+
+	// the uat tag needs the test tag on the commit the release is made from
+	if stdout, err := run("--dry", "--require-sign-off", "uat"); err == nil || !strings.Contains(stdout, gittaginc.ErrCodeSignOff) {
+		t.Errorf("uat without the test tag on HEAD was allowed: %s", stdout)
+	}
+	c2, _ := r.Head()
+	if err := r.DeleteTag("v1.0.0-rc.01.test.01"); err != nil {
+		t.Fatal(err)
+	}
+	testTag(t, r, "v1.0.0-rc.01.test.01", c2.Hash(), false)
+	// the file's version is tagged on HEAD now, which is a repeat
+	if stdout, err := run("--dry", "--repeating", "--require-sign-off", "uat"); err != nil {
+		t.Errorf("uat with the test tag on HEAD refused: %v %s", err, stdout)
+	}
+}
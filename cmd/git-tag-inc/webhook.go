@@ -0,0 +1,106 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arran4/git-tag-inc"
+	"github.com/go-git/go-git/v5"
+)
+
+// webhookTimeout bounds the delivery to one hook, retries included.
+const webhookTimeout = 2 * time.Minute
+
+// webhookTarget is a configured hook with its secret read from the
+// environment.
+type webhookTarget struct {
+	hook   gittaginc.Webhook
+	secret string
+}
+
+// reportWebhook is the outcome of one delivery in the JSON output.
+type reportWebhook struct {
+	URL       string `json:"url"`
+	Delivered bool   `json:"delivered"`
+	Error     string `json:"error,omitempty"`
+}
+
+// loadWebhooks reads the secrets of the configured hooks, failing before
+// anything is tagged when one is missing.
+func loadWebhooks(cfg *gittaginc.Config) []webhookTarget {
+	var targets []webhookTarget
+	for _, h := range cfg.Webhooks {
+		t := webhookTarget{hook: h}
+		if h.SecretEnv != "" {
+			if t.secret = os.Getenv(h.SecretEnv); t.secret == "" {
+				fail(ErrCodeConfig, "Webhook %s needs a secret in $%s", h.URL, h.SecretEnv)
+			}
+		}
+		targets = append(targets, t)
+	}
+	return targets
+}
+
+// webhookRepository describes r for webhook payloads from its origin
+// remote, or the name of its directory when there is none, so the payload
+// does not reveal where the repository lives on this machine.
+func webhookRepository(r *git.Repository) gittaginc.WebhookRepository {
+	var repo gittaginc.WebhookRepository
+	if remote, err := r.Remote("origin"); err == nil && len(remote.Config().URLs) > 0 {
+		repo.URL = withoutUserinfo(remote.Config().URLs[0])
+		repo.Owner, repo.Name, _ = gittaginc.ParseRemoteRepo(repo.URL)
+	}
+	if repo.Name == "" {
+		if wt, err := r.Worktree(); err == nil {
+			repo.Name = filepath.Base(wt.Filesystem.Root())
+		}
+	}
+	return repo
+}
+
+// withoutUserinfo drops the user and password from a remote URL, such as the
+// job token GitLab CI puts in https://gitlab-ci-token:<token>@host/..., so
+// they are not sent to third parties. scp-like addresses are kept as they are.
+func withoutUserinfo(remote string) string {
+	u, err := url.Parse(remote)
+	if err != nil || u.User == nil {
+		return remote
+	}
+	u.User = nil
+	return u.String()
+}
+
+// notifyWebhooks posts the new tag to every hook matching it. Failures are
+// reported but the tag is kept and the run still succeeds.
+func notifyWebhooks(r *git.Repository, targets []webhookTarget, res gittaginc.BumpResult, tagger *gittaginc.Signature) {
+	payload := gittaginc.NewWebhookPayload(res.Previous, res.Tag, res.Target, tagger, webhookRepository(r))
+	sender := &gittaginc.WebhookSender{}
+	for _, t := range targets {
+		if !t.hook.Matches(res.Tag) {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+		err := sender.Send(ctx, t.hook, t.secret, payload)
+		cancel()
+		rw := reportWebhook{URL: t.hook.URL, Delivered: err == nil}
+		if err != nil {
+			rw.Error = err.Error()
+			fmt.Fprintf(out, "Failed to notify webhook, %s was still created: %v\n", res.Tag, err)
+		} else {
+			fmt.Fprintf(out, "Notified %s\n", t.hook.URL)
+		}
+		if report != nil {
+			report.Webhooks = append(report.Webhooks, rw)
+		}
+	}
+}
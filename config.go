@@ -0,0 +1,66 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// ConfigFile is the name of the configuration file looked for at the top of
+// the worktree.
+const ConfigFile = ".git-tag-inc.json"
+
+// Config holds the settings a repository can keep in ConfigFile.
+type Config struct {
+	// Transitions replaces DefaultStageTransitions when set.
+	Transitions StageTransitions `json:"transitions,omitempty"`
+	// Qualifiers are extra version components such as hotfix, see
+	// NewParser.
+	Qualifiers []Qualifier `json:"qualifiers,omitempty"`
+	// RequireSignOff turns on the sign-off gate, see CheckSignOff.
+	RequireSignOff bool `json:"require_sign_off,omitempty"`
+	// LineBranch matches branch names that select a maintenance line, see
+	// LineFromBranch.
+	LineBranch string `json:"line_branch,omitempty"`
+	// Release says where --release creates releases.
+	Release *ReleaseConfig `json:"release,omitempty"`
+	// Webhooks are notified of each tag created, see Webhook.
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+}
+
+// LoadConfig reads and checks a configuration file. Unknown fields are an
+// error so that typos are not silently ignored.
+func LoadConfig(path string) (*Config, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	c := &Config{}
+	if err := dec.Decode(c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := c.Transitions.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ValidateQualifiers(c.Qualifiers); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if err := ValidateWebhooks(c.Webhooks); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if c.LineBranch != "" {
+		if _, err := CompileLineBranch(c.LineBranch); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return c, nil
+}
@@ -0,0 +1,55 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+	write := func(content string) string {
+		path := filepath.Join(dir, ConfigFile)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := LoadConfig(write(`{"transitions": {"alpha": ["beta"], "beta": ["release"]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := StageTransitions{"alpha": {"beta"}, "beta": {StageRelease}}
+	if !reflect.DeepEqual(cfg.Transitions, want) {
+		t.Errorf("got %v, want %v", cfg.Transitions, want)
+	}
+
+	cfg, err = LoadConfig(write(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Transitions != nil {
+		t.Errorf("expected no transitions, got %v", cfg.Transitions)
+	}
+
+	for _, bad := range []string{
+		`{"transitons": {}}`,
+		`{"transitions": {"alpha": ["gamma"]}}`,
+		`not json`,
+	} {
+		if _, err := LoadConfig(write(bad)); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+	if _, err := LoadConfig(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Describe returns a pseudo version for a commit that is distance commits
+// past base, in the spirit of `git describe`. For example v1.2.4-test.3 with
+// a distance of 7 at abc1234 becomes v1.2.4-test.3.dev.7+gabc1234. A release
+// base has its patch bumped first, as a pre-release of it would sort below
+// it, so v1.2.4 with a distance of 2 becomes v1.2.5-dev.2. A distance of zero
+// means the commit is the tagged one and base is returned unchanged.
+func Describe(base *Tag, distance int, shortHash string) string {
+	if distance <= 0 {
+		return base.String()
+	}
+	if isRelease(base) {
+		next := base.Clone()
+		next.Patch++
+		next.Release = nil
+		base = next
+	}
+	s := base.String()
+	switch {
+	case base.Mode == ModeLegacy || base.Mode == ModeArraneous:
+		s += fmt.Sprintf("-dev%d", distance)
+	case strings.Contains(s, "-"):
+		s += fmt.Sprintf(".dev.%d", distance)
+	default:
+		s += fmt.Sprintf("-dev.%d", distance)
+	}
+	if shortHash != "" {
+		s += "+g" + shortHash
+	}
+	return s
+}
+
+// isRelease reports whether t is a plain version other than the empty
+// v0.0.0 that stands for no tag at all.
+func isRelease(t *Tag) bool {
+	_, env := EnvInfo(t)
+	if t.Stage != nil || t.Qualifier != nil || env != nil {
+		return false
+	}
+	return t.Major != 0 || t.Minor != 0 || t.Patch != 0 || t.Release != nil
+}
+
+// CommitWalker walks the history of a repository for DescribeCommit.
+type CommitWalker interface {
+	// Ancestors returns every commit reachable from commit, including
+	// commit itself.
+	Ancestors(commit string) (map[string]struct{}, error)
+}
+
+// Description places a commit relative to the nearest version tag.
+type Description struct {
+	// Base is the highest version tag reachable from Commit, or v0.0.0 when
+	// none is.
+	Base *Tag
+	// Distance is the number of commits Commit is ahead of Base.
+	Distance int
+	Commit   string
+}
+
+// Version returns the pseudo version of the description, with the commit
+// hash abbreviated to abbrev characters, or in full when abbrev is zero.
+func (d *Description) Version(abbrev int) string {
+	short := d.Commit
+	if abbrev > 0 && abbrev < len(short) {
+		short = short[:abbrev]
+	}
+	return Describe(d.Base, d.Distance, short)
+}
+
+// DescribeCommit finds the highest version tag in src reachable from commit
+// and the number of commits commit is ahead of it. When no version tag is
+// reachable the base is v0.0.0 in mode, or semver when mode is "auto", and
+// the distance counts every reachable commit. Unless mode is "auto" it
+// overrides each tag's Mode.
+func DescribeCommit(src TagSource, walker CommitWalker, commit, mode string) (*Description, error) {
+	reachable, err := walker.Ancestors(commit)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := VersionTags(src, mode)
+	if err != nil {
+		return nil, err
+	}
+	var base *Tag
+	var baseCommit string
+	for _, t := range tags {
+		c, err := PeelTag(src, t.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("resolving %s: %w", t, err)
+		}
+		if _, ok := reachable[c]; !ok {
+			continue
+		}
+		if base == nil || base.LessThan(t) {
+			base = t
+			baseCommit = c
+		}
+	}
+
+	d := &Description{Base: base, Distance: len(reachable), Commit: commit}
+	if base == nil {
+		startMode := mode
+		if mode == "auto" {
+			startMode = ModeSemver
+		}
+		d.Base = &Tag{Mode: startMode}
+		return d, nil
+	}
+	covered, err := walker.Ancestors(baseCommit)
+	if err != nil {
+		return nil, err
+	}
+	d.Distance -= len(covered)
+	return d, nil
+}
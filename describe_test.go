@@ -0,0 +1,106 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		base     string
+		mode     string
+		distance int
+		hash     string
+		want     string
+	}{
+		{"v1.2.4-test.3", ModeSemver, 7, "abc1234", "v1.2.4-test.3.dev.7+gabc1234"},
+		{"v1.2.4", ModeSemver, 2, "abc1234", "v1.2.5-dev.2+gabc1234"},
+		{"v1.2.4-3", ModeLegacy, 2, "", "v1.2.5-dev2"},
+		{"v1.2.4", ModeSemver, 0, "", "v1.2.4"},
+		{"v1.2.4-rc.01.uat.02", ModeSemver, 1, "", "v1.2.4-rc.01.uat.02.dev.1"},
+		{"v1.2.4-test03", ModeLegacy, 7, "abc1234", "v1.2.4-test03-dev7+gabc1234"},
+		{"v1.2.4-test.3", ModeSemver, 0, "abc1234", "v1.2.4-test.3"},
+	}
+	for _, tt := range cases {
+		base := ParseTag(tt.base)
+		base.Mode = tt.mode
+		if got := Describe(base, tt.distance, tt.hash); got != tt.want {
+			t.Errorf("Describe(%s, %d, %s) got %s want %s", tt.base, tt.distance, tt.hash, got, tt.want)
+		}
+	}
+}
+
+func TestDescribeCommit(t *testing.T) {
+	r, err := git.PlainInit(t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(msg string) string {
+		t.Helper()
+		sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+		h, err := w.Commit(msg, &git.CommitOptions{Author: sig, AllowEmptyCommits: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h.String()
+	}
+	src := NewGoGitTagSource(r)
+	c1 := commit("one")
+
+	untagged, err := DescribeCommit(src, src, c1, "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := untagged.Version(7), "v0.0.0-dev.1+g"+c1[:7]; got != want || untagged.Distance != 1 {
+		t.Errorf("untagged got %s (%d) want %s", got, untagged.Distance, want)
+	}
+
+	tagger := &Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+	if err := src.CreateTag("v1.2.4-test.3", c1, &CreateTagOptions{Message: "v1.2.4-test.3", Tagger: tagger}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.CreateTag("v1.2.3", c1, nil); err != nil {
+		t.Fatal(err)
+	}
+	exact, err := DescribeCommit(src, src, c1, "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exact.Version(7) != "v1.2.4-test.3" || exact.Distance != 0 {
+		t.Errorf("exact got %s at %d", exact.Version(7), exact.Distance)
+	}
+
+	commit("two")
+	c3 := commit("three")
+	// a tag on a later commit is not reachable from c1
+	if err := src.CreateTag("v2.0.0", c3, nil); err != nil {
+		t.Fatal(err)
+	}
+	before, err := DescribeCommit(src, src, c1, "auto")
+	if err != nil || before.Base.String() != "v1.2.4-test.3" {
+		t.Errorf("before got %v, %v", before, err)
+	}
+	if err := r.DeleteTag("v2.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	ahead, err := DescribeCommit(src, src, c3, ModeLegacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := ahead.Version(0), "v1.2.4-test3-dev2+g"+c3; got != want {
+		t.Errorf("legacy ahead got %s want %s", got, want)
+	}
+}
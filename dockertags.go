@@ -0,0 +1,66 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DockerTagLatest is the rolling container image tag of the highest release.
+const DockerTagLatest = "latest"
+
+// isPlainRelease reports whether t is a release without a stage, qualifier,
+// environment or release counter.
+func isPlainRelease(t *Tag) bool {
+	return t.Stage == nil && t.Qualifier == nil && t.Test == nil && t.Uat == nil && t.Release == nil
+}
+
+// DockerTags returns the container image tags for t, its version without the
+// leading v first. A plain release also gets the rolling major.minor, major
+// and latest tags, and an environment build its environment's name, unless a
+// higher release, or a higher build of the environment, in existing already
+// holds that tag. existing may include t itself.
+func DockerTags(t *Tag, existing []*Tag) []string {
+	tags := []string{strings.TrimPrefix(t.String(), "v")}
+	if isPlainRelease(t) {
+		minor, major, latest := true, true, true
+		for _, e := range existing {
+			if !isPlainRelease(e) || !t.LessThan(e) {
+				continue
+			}
+			latest = false
+			if e.Major == t.Major {
+				major = false
+				if e.Minor == t.Minor {
+					minor = false
+				}
+			}
+		}
+		if minor {
+			tags = append(tags, fmt.Sprintf("%d.%d", t.Major, t.Minor))
+		}
+		if major {
+			tags = append(tags, strconv.Itoa(t.Major))
+		}
+		if latest {
+			tags = append(tags, DockerTagLatest)
+		}
+		return tags
+	}
+	env, _ := EnvInfo(t)
+	if env == "" {
+		return tags
+	}
+	for _, e := range existing {
+		if eEnv, _ := EnvInfo(e); eEnv == env && t.LessThan(e) {
+			return tags
+		}
+	}
+	return append(tags, env)
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDockerTags(t *testing.T) {
+	var existing []*Tag
+	for _, name := range []string{"v1.4.1", "v1.4.2", "v1.5.0-rc.01", "v1.3.9", "v1.4.2-uat.02", "v1.4.3-test.01", "v2.0.0-test.01"} {
+		existing = append(existing, ParseTag(name))
+	}
+	for _, tt := range []struct {
+		tag      string
+		existing []*Tag
+		want     []string
+	}{
+		{"v1.4.2", existing, []string{"1.4.2", "1.4", "1", "latest"}},
+		{"v1.4.2", nil, []string{"1.4.2", "1.4", "1", "latest"}},
+		{"v1.4.1", existing, []string{"1.4.1"}},
+		{"v1.3.10", existing, []string{"1.3.10", "1.3"}},
+		{"v0.9.0", existing, []string{"0.9.0", "0.9", "0"}},
+		{"v1.4.2-uat.03", existing, []string{"1.4.2-uat.03", "uat"}},
+		{"v1.4.2-test.01", existing, []string{"1.4.2-test.01"}},
+		{"v1.5.0-rc.02", existing, []string{"1.5.0-rc.02"}},
+	} {
+		if got := DockerTags(ParseTag(tt.tag), tt.existing); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.tag, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// explainf writes one step of an explanation to w, if there is one.
+func explainf(w io.Writer, format string, args ...interface{}) {
+	if w == nil {
+		return
+	}
+	fmt.Fprintf(w, format+"\n", args...)
+}
+
+// componentValue shows the part of t that compare names by.
+func componentValue(t *Tag, by string) string {
+	optional := func(v *int) string {
+		if v == nil {
+			return "none"
+		}
+		return strconv.Itoa(*v)
+	}
+	switch by {
+	case "major":
+		return strconv.Itoa(t.Major)
+	case "minor":
+		return strconv.Itoa(t.Minor)
+	case "patch":
+		return strconv.Itoa(t.Patch)
+	case "stage":
+		if t.StageName == "" {
+			return "none"
+		}
+		return t.StageName
+	case "stage counter":
+		return optional(t.Stage)
+	case "qualifier":
+		if t.QualifierName == "" {
+			return "none"
+		}
+		return t.QualifierName
+	case "qualifier counter":
+		return optional(t.Qualifier)
+	case "environment":
+		if env, _ := EnvInfo(t); env != "" {
+			return env
+		}
+		return "none"
+	case "environment counter":
+		_, v := EnvInfo(t)
+		return optional(v)
+	case "release":
+		return optional(t.Release)
+	}
+	return ""
+}
+
+// explainRanking writes why each of tags is not above highest.
+func explainRanking(w io.Writer, tags []*Tag, highest *Tag) {
+	if w == nil {
+		return
+	}
+	explainf(w, "highest: %s out of %d version tag(s)", highest, len(tags))
+	for _, t := range tags {
+		if t == highest {
+			continue
+		}
+		less, by := t.compare(highest)
+		switch {
+		case !less && by == "":
+			explainf(w, "  %s ties with %s", t, highest)
+		case !less:
+			explainf(w, "  %s is not below %s on %s (%s vs %s), keeping the one found first", t, highest, by, componentValue(t, by), componentValue(highest, by))
+		default:
+			explainf(w, "  %s lost on %s: %s < %s", t, by, componentValue(t, by), componentValue(highest, by))
+		}
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestIncrementExplain(t *testing.T) {
+	tests := []struct {
+		tag   string
+		cmds  []string
+		skip  bool
+		lines []string
+	}{
+		{"v1.0.0-test.03", []string{"patch"}, false, []string{
+			"patch: stays 0 because v1.0.0-test.03 has a stage or environment",
+			"result: v1.0.0",
+		}},
+		{"v1.0.0-uat.03", []string{"test"}, false, []string{
+			"test: keeps the counter 3 of the previous uat tag",
+			"test: 2 digits, the default",
+		}},
+		{"v1.0.0-beta.01.test.02", []string{"rc"}, false, []string{
+			"transition: beta -> rc is allowed",
+			"patch: 0 -> 1 because rc starts a new stage",
+			"test: dropped because the stage changed",
+		}},
+		{"v1.0.0-test3", []string{"test2"}, true, []string{
+			"went backwards: test from 3 to 2",
+			"skip-forwards: retrying with patch 1",
+			"result: v1.0.1-test02",
+		}},
+		{"v1.0.0-rc.03", []string{"alpha"}, false, []string{
+			"transition: rc -> alpha is not allowed",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag+" "+strings.Join(tt.cmds, " "), func(t *testing.T) {
+			var buf bytes.Buffer
+			_ = ParseTag(tt.tag).IncrementWithOptions(CommandsToFlags(tt.cmds, "auto"), IncrementOptions{SkipForwards: tt.skip, Explain: &buf})
+			for _, line := range tt.lines {
+				if !strings.Contains(buf.String(), line) {
+					t.Errorf("missing %q in:\n%s", line, buf.String())
+				}
+			}
+		})
+	}
+}
+
+func TestExplainHighestVersionTag(t *testing.T) {
+	src := NewMemoryTagSource("c1")
+	for _, name := range []string{"v1.0.0", "v1.2.0-rc.01", "v1.2.0-beta.04", "v1.2.0-rc.01.test.02", "v0.9.0"} {
+		if err := src.CreateTag(name, "c1", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf bytes.Buffer
+	highest, err := ExplainHighestVersionTag(src, "auto", &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if highest.String() != "v1.2.0-rc.01" {
+		t.Errorf("got %s", highest)
+	}
+	for _, line := range []string{
+		"highest: v1.2.0-rc.01 out of 5 version tag(s)",
+		"v1.0.0 lost on minor: 0 < 2",
+		"v1.2.0-beta.04 lost on stage: beta < rc",
+		"v1.2.0-rc.01.test.02 lost on environment: test < none",
+		"v0.9.0 lost on major: 0 < 1",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("missing %q in:\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestCmdFlagsString(t *testing.T) {
+	for _, cmds := range [][]string{{"patch", "rc02", "test"}, {"major3", "minor", "uat"}, {"release2"}} {
+		if got := CommandsToFlags(cmds, "auto").String(); got != strings.Join(cmds, " ") {
+			t.Errorf("%v: got %q", cmds, got)
+		}
+	}
+	if got := CommandsToFlags([]string{"release"}, ModeArraneous).String(); got != "release" {
+		t.Errorf("arraneous release: got %q", got)
+	}
+}
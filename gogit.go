@@ -0,0 +1,353 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5"
+	"github.com/go-git/go-billy/v5/util"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// ErrTagLocked is returned by GoGitTagSource.CreateTag when another process
+// holds the tag's lock file for longer than RefLockTimeout.
+var ErrTagLocked = errors.New("tag is locked by another process")
+
+// RefLockTimeout is how long CreateTag waits for another process's lock on
+// the same tag.
+var RefLockTimeout = 5 * time.Second
+
+// ErrNotCommit is returned by PeelToCommit when a tag ends at a tree or blob.
+var ErrNotCommit = errors.New("tag does not point at a commit")
+
+// maxPeelDepth bounds PeelToCommit so a malformed repository cannot loop.
+const maxPeelDepth = 32
+
+// PeelToCommit follows h through any chain of annotated tags and returns the
+// commit at the end. The hash of a lightweight tag is already a commit and is
+// returned unchanged.
+func PeelToCommit(r *git.Repository, h plumbing.Hash) (plumbing.Hash, error) {
+	for i := 0; i < maxPeelDepth; i++ {
+		obj, err := r.Storer.EncodedObject(plumbing.AnyObject, h)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		switch obj.Type() {
+		case plumbing.CommitObject:
+			return h, nil
+		case plumbing.TagObject:
+			to, err := object.DecodeTag(r.Storer, obj)
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			h = to.Target
+		default:
+			return plumbing.ZeroHash, fmt.Errorf("%s is a %s: %w", h, obj.Type(), ErrNotCommit)
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("tag chain at %s is deeper than %d", h, maxPeelDepth)
+}
+
+// GoGitTagSource is a TagSource backed by a go-git repository.
+type GoGitTagSource struct {
+	Repository *git.Repository
+	// Parser reads the tags, nil for no qualifiers.
+	Parser *Parser
+}
+
+var (
+	_ TagSource          = (*GoGitTagSource)(nil)
+	_ TagPeeler          = (*GoGitTagSource)(nil)
+	_ CommitWalker       = (*GoGitTagSource)(nil)
+	_ WorktreeStatus     = (*GoGitTagSource)(nil)
+	_ QualifiedTagSource = (*GoGitTagSource)(nil)
+)
+
+func NewGoGitTagSource(r *git.Repository) *GoGitTagSource {
+	return &GoGitTagSource{Repository: r}
+}
+
+func (s *GoGitTagSource) TagParser() *Parser {
+	return s.Parser
+}
+
+// Tags lists the tag references. On disk they are read directly rather than
+// through go-git's iterator, which also reads the refs/tags/<name>.lock files
+// CreateTag writes and fails on one that is still empty. No valid reference
+// name ends in .lock, so those files are always skipped.
+func (s *GoGitTagSource) Tags() ([]TagRef, error) {
+	if st, ok := s.Repository.Storer.(*filesystem.Storage); ok {
+		return readTagRefs(st.Filesystem())
+	}
+	iter, err := s.Repository.Tags()
+	if err != nil {
+		return nil, err
+	}
+	var refs []TagRef
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		refs = append(refs, TagRef{Name: ref.Name().Short(), Hash: ref.Hash().String()})
+		return nil
+	})
+	return refs, err
+}
+
+// readTagRefs reads the loose references under refs/tags followed by the
+// packed ones they do not override, the order go-git lists them in.
+func readTagRefs(fs billy.Filesystem) ([]TagRef, error) {
+	var refs []TagRef
+	seen := map[string]bool{}
+	if err := readLooseTagRefs(fs, "refs/tags", &refs, seen); err != nil {
+		return nil, err
+	}
+	f, err := fs.Open("packed-refs")
+	if os.IsNotExist(err) {
+		return refs, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) != 2 || strings.HasPrefix(fields[0], "#") || strings.HasPrefix(fields[0], "^") {
+			continue
+		}
+		ref := plumbing.NewReferenceFromStrings(fields[1], fields[0])
+		if !ref.Name().IsTag() || seen[ref.Name().Short()] {
+			continue
+		}
+		refs = append(refs, TagRef{Name: ref.Name().Short(), Hash: ref.Hash().String()})
+	}
+	return refs, sc.Err()
+}
+
+func readLooseTagRefs(fs billy.Filesystem, dir string, refs *[]TagRef, seen map[string]bool) error {
+	files, err := fs.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	for _, fi := range files {
+		name := path.Join(dir, fi.Name())
+		if fi.IsDir() {
+			if err := readLooseTagRefs(fs, name, refs, seen); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasSuffix(fi.Name(), ".lock") {
+			continue
+		}
+		b, err := util.ReadFile(fs, name)
+		if os.IsNotExist(err) {
+			// deleted since the directory was read
+			continue
+		} else if err != nil {
+			return err
+		}
+		line := strings.TrimSpace(string(b))
+		if line == "" {
+			// a broken reference, which git ignores as well
+			continue
+		}
+		ref := plumbing.NewReferenceFromStrings(name, line)
+		if ref.Type() != plumbing.HashReference {
+			continue
+		}
+		*refs = append(*refs, TagRef{Name: ref.Name().Short(), Hash: ref.Hash().String()})
+		seen[ref.Name().Short()] = true
+	}
+	return nil
+}
+
+func (s *GoGitTagSource) ResolveTag(name string) (string, error) {
+	ref, err := s.Repository.Tag(name)
+	if errors.Is(err, git.ErrTagNotFound) {
+		return "", fmt.Errorf("%s: %w", name, ErrTagNotFound)
+	} else if err != nil {
+		return "", err
+	}
+	return s.resolveHash(ref.Hash())
+}
+
+// PeelTag returns the commit a tag reference hash from Tags points at.
+func (s *GoGitTagSource) PeelTag(hash string) (string, error) {
+	return s.resolveHash(plumbing.NewHash(hash))
+}
+
+// resolveHash returns the commit a tag reference's hash points at. Tags that
+// end at something other than a commit resolve to an empty hash.
+func (s *GoGitTagSource) resolveHash(h plumbing.Hash) (string, error) {
+	commit, err := PeelToCommit(s.Repository, h)
+	if errors.Is(err, ErrNotCommit) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	return commit.String(), nil
+}
+
+// Ancestors returns every commit reachable from commit, including itself.
+func (s *GoGitTagSource) Ancestors(commit string) (map[string]struct{}, error) {
+	c, err := s.Repository.CommitObject(plumbing.NewHash(commit))
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]struct{}{}
+	err = object.NewCommitPreorderIter(c, nil, nil).ForEach(func(c *object.Commit) error {
+		seen[c.Hash.String()] = struct{}{}
+		return nil
+	})
+	return seen, err
+}
+
+func (s *GoGitTagSource) Head() (string, error) {
+	ref, err := s.Repository.Head()
+	if err != nil {
+		return "", err
+	}
+	return ref.Hash().String(), nil
+}
+
+// CreateTag creates the tag. On disk it follows git's locking protocol: the
+// reference is written to refs/tags/<name>.lock, which only one process can
+// create, and renamed into place, so concurrent callers never both succeed
+// and readers never see a partly written reference.
+func (s *GoGitTagSource) CreateTag(name, target string, opts *CreateTagOptions) error {
+	var gitOpts *git.CreateTagOptions
+	if opts != nil {
+		gitOpts = &git.CreateTagOptions{Message: opts.Message}
+		if opts.Tagger != nil {
+			gitOpts.Tagger = &object.Signature{
+				Name:  opts.Tagger.Name,
+				Email: opts.Tagger.Email,
+				When:  opts.Tagger.When,
+			}
+		}
+	}
+	st, ok := s.Repository.Storer.(*filesystem.Storage)
+	if !ok {
+		_, err := s.Repository.CreateTag(name, plumbing.NewHash(target), gitOpts)
+		if errors.Is(err, git.ErrTagExists) {
+			return fmt.Errorf("%s: %w", name, ErrTagExists)
+		}
+		return err
+	}
+	return s.createTagLocked(st.Filesystem(), name, plumbing.NewHash(target), gitOpts)
+}
+
+func (s *GoGitTagSource) createTagLocked(fs billy.Filesystem, name string, target plumbing.Hash, opts *git.CreateTagOptions) error {
+	rname := plumbing.NewTagReferenceName(name)
+	if err := rname.Validate(); err != nil {
+		return err
+	}
+	refPath := rname.String()
+	lock := refPath + ".lock"
+	f, err := lockFile(fs, lock)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	renamed := false
+	defer func() {
+		_ = f.Close()
+		if !renamed {
+			_ = fs.Remove(lock)
+		}
+	}()
+
+	if _, err := s.Repository.Storer.Reference(rname); err == nil {
+		return fmt.Errorf("%s: %w", name, ErrTagExists)
+	} else if !errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return err
+	}
+	hash := target
+	if opts != nil {
+		if hash, err = s.createTagObject(name, target, opts); err != nil {
+			return err
+		}
+	}
+	if _, err := f.Write([]byte(hash.String() + "\n")); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := fs.Rename(lock, refPath); err != nil {
+		return err
+	}
+	renamed = true
+	return nil
+}
+
+// createTagObject stores an annotated tag object for target.
+func (s *GoGitTagSource) createTagObject(name string, target plumbing.Hash, opts *git.CreateTagOptions) (plumbing.Hash, error) {
+	if err := opts.Validate(s.Repository, target); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	obj, err := s.Repository.Storer.EncodedObject(plumbing.AnyObject, target)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	tag := &object.Tag{
+		Name:       name,
+		Tagger:     *opts.Tagger,
+		Message:    opts.Message,
+		TargetType: obj.Type(),
+		Target:     target,
+	}
+	enc := s.Repository.Storer.NewEncodedObject()
+	if err := tag.Encode(enc); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return s.Repository.Storer.SetEncodedObject(enc)
+}
+
+// lockFile creates name exclusively, waiting up to RefLockTimeout while
+// another process holds it.
+func lockFile(fs billy.Filesystem, name string) (billy.File, error) {
+	if err := fs.MkdirAll(path.Dir(name), 0755); err != nil {
+		return nil, err
+	}
+	deadline := time.Now().Add(RefLockTimeout)
+	for {
+		f, err := fs.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			return f, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("%w (remove .git/%s if no other process is running)", ErrTagLocked, name)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// IsClean reports whether the worktree has no uncommitted changes.
+func (s *GoGitTagSource) IsClean() (bool, error) {
+	wt, err := s.Repository.Worktree()
+	if err != nil {
+		return false, err
+	}
+	st, err := wt.Status()
+	if err != nil {
+		return false, err
+	}
+	return st.IsClean(), nil
+}
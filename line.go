@@ -0,0 +1,167 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Line is a maintenance line, every version sharing a major and minor
+// number, such as the 1.2 in v1.2.7.
+type Line struct {
+	Major int
+	Minor int
+}
+
+// ParseLine parses a line written as "1.2" or "v1.2".
+func ParseLine(s string) (*Line, error) {
+	major, minor, ok := strings.Cut(strings.TrimPrefix(s, "v"), ".")
+	if !ok {
+		return nil, fmt.Errorf("invalid line %q, expected major.minor such as 1.2", s)
+	}
+	l := &Line{}
+	var err error
+	if l.Major, err = strconv.Atoi(major); err != nil || l.Major < 0 {
+		return nil, fmt.Errorf("invalid line %q, expected major.minor such as 1.2", s)
+	}
+	if l.Minor, err = strconv.Atoi(minor); err != nil || l.Minor < 0 {
+		return nil, fmt.Errorf("invalid line %q, expected major.minor such as 1.2", s)
+	}
+	return l, nil
+}
+
+func (l *Line) String() string {
+	return fmt.Sprintf("%d.%d", l.Major, l.Minor)
+}
+
+// Contains reports whether t is on the line.
+func (l *Line) Contains(t *Tag) bool {
+	return t.Major == l.Major && t.Minor == l.Minor
+}
+
+// CompileLineBranch compiles a pattern matching branch names, whose first
+// two groups are a line's major and minor numbers.
+func CompileLineBranch(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("line branch pattern: %w", err)
+	}
+	if re.NumSubexp() < 2 {
+		return nil, fmt.Errorf("line branch pattern %q needs two groups, for the major and minor numbers", pattern)
+	}
+	return re, nil
+}
+
+// LineFromBranch returns the line named by branch when the whole name
+// matches pattern, or nil when it does not.
+func LineFromBranch(pattern, branch string) (*Line, error) {
+	re, err := CompileLineBranch(pattern)
+	if err != nil {
+		return nil, err
+	}
+	m := re.FindStringSubmatch(branch)
+	if m == nil || m[0] != branch {
+		return nil, nil
+	}
+	return ParseLine(m[1] + "." + m[2])
+}
+
+// LineTagSource only lists the version tags on one line, so the highest tag
+// found through it is the highest of that line.
+type LineTagSource struct {
+	TagSource
+	Line *Line
+}
+
+var (
+	_ TagSource          = (*LineTagSource)(nil)
+	_ ParsedTagSource    = (*LineTagSource)(nil)
+	_ QualifiedTagSource = (*LineTagSource)(nil)
+)
+
+func NewLineTagSource(src TagSource, line *Line) *LineTagSource {
+	return &LineTagSource{TagSource: src, Line: line}
+}
+
+// Tags lists the tags of the wrapped source that are versions on the line.
+func (s *LineTagSource) Tags() ([]TagRef, error) {
+	refs, err := s.TagSource.Tags()
+	if err != nil {
+		return nil, err
+	}
+	p := SourceParser(s.TagSource)
+	var onLine []TagRef
+	for _, ref := range refs {
+		if t := p.ParseTag(ref.Name); t != nil && s.Line.Contains(t) {
+			onLine = append(onLine, ref)
+		}
+	}
+	return onLine, nil
+}
+
+// TagParser returns the parser of the wrapped source.
+func (s *LineTagSource) TagParser() *Parser {
+	return SourceParser(s.TagSource)
+}
+
+// ParsedTags returns the version tags of the wrapped source that are on the
+// line, using its parsed tags when it has them.
+func (s *LineTagSource) ParsedTags() ([]*Tag, error) {
+	tags, err := VersionTags(s.TagSource, "auto")
+	if err != nil {
+		return nil, err
+	}
+	var onLine []*Tag
+	for _, t := range tags {
+		if s.Line.Contains(t) {
+			onLine = append(onLine, t)
+		}
+	}
+	return onLine, nil
+}
+
+// PeelTag forwards to the wrapped source.
+func (s *LineTagSource) PeelTag(hash string) (string, error) {
+	return PeelTag(s.TagSource, hash)
+}
+
+// IsClean forwards to the wrapped source so Bump can still check the worktree.
+func (s *LineTagSource) IsClean() (bool, error) {
+	ws, ok := s.TagSource.(WorktreeStatus)
+	if !ok {
+		return false, fmt.Errorf("tag source cannot report worktree status")
+	}
+	return ws.IsClean()
+}
+
+// LineError is returned by Bump when the commands would leave the line or
+// the line has no tags to continue from.
+type LineError struct {
+	Line    *Line
+	Command string
+}
+
+func (e *LineError) Error() string {
+	if e.Command == "" {
+		return fmt.Sprintf("there are no tags on line %s to continue from", e.Line)
+	}
+	return fmt.Sprintf("%s would leave line %s; only patch and the commands after it may be used on a maintenance line", e.Command, e.Line)
+}
+
+// checkLine returns a *LineError when flags would move off line.
+func checkLine(line *Line, flags CmdFlags) error {
+	switch {
+	case flags.Major:
+		return &LineError{Line: line, Command: "major"}
+	case flags.Minor:
+		return &LineError{Line: line, Command: "minor"}
+	}
+	return nil
+}
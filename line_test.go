@@ -0,0 +1,104 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestParseLine(t *testing.T) {
+	for in, want := range map[string]string{"1.2": "1.2", "v10.0": "10.0"} {
+		l, err := ParseLine(in)
+		if err != nil || l.String() != want {
+			t.Errorf("%s: got %v, %v", in, l, err)
+		}
+	}
+	for _, bad := range []string{"", "1", "1.x", "1.-2", "v1.2.3"} {
+		if l, err := ParseLine(bad); err == nil {
+			t.Errorf("%s: expected an error, got %v", bad, l)
+		}
+	}
+}
+
+func TestLineFromBranch(t *testing.T) {
+	const pattern = `release/(\d+)\.(\d+)`
+	l, err := LineFromBranch(pattern, "release/1.2")
+	if err != nil || l == nil || *l != (Line{Major: 1, Minor: 2}) {
+		t.Errorf("got %v, %v", l, err)
+	}
+	for _, branch := range []string{"main", "release/1.2-fixes", "old/release/1.2"} {
+		if l, err := LineFromBranch(pattern, branch); err != nil || l != nil {
+			t.Errorf("%s: got %v, %v", branch, l, err)
+		}
+	}
+	if _, err := LineFromBranch(`release/(\d+)`, "release/1"); err == nil {
+		t.Errorf("expected a pattern with one group to fail")
+	}
+}
+
+func TestBumpLine(t *testing.T) {
+	ctx := context.Background()
+	src := NewMemoryTagSource("c1")
+	for _, name := range []string{"v1.2.3", "v1.2.4-test.02", "v1.5.0", "v2.0.0"} {
+		if err := src.CreateTag(name, "c0", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	line := &Line{Major: 1, Minor: 2}
+
+	res, err := Bump(ctx, src, BumpOptions{Commands: []string{"test"}, Line: line})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Previous.String() != "v1.2.4-test.02" || res.Tag.String() != "v1.2.4-test.03" {
+		t.Errorf("got %s from %s", res.Tag, res.Previous)
+	}
+
+	for _, cmd := range []string{"minor", "major"} {
+		_, err := Bump(ctx, src, BumpOptions{Commands: []string{cmd}, Line: line, Dry: true})
+		if code := ErrorCode(err); code != ErrCodeLine {
+			t.Errorf("%s: expected %s, got %q (%v)", cmd, ErrCodeLine, code, err)
+		}
+	}
+	_, err = Bump(ctx, src, BumpOptions{Commands: []string{"patch"}, Line: &Line{Major: 1, Minor: 3}, Dry: true})
+	if code := ErrorCode(err); code != ErrCodeLine {
+		t.Errorf("empty line: expected %s, got %q (%v)", ErrCodeLine, code, err)
+	}
+}
+
+// parsedOnlySource only lists its tags through ParsedTags, as a cache would.
+type parsedOnlySource struct {
+	*MemoryTagSource
+}
+
+func (s parsedOnlySource) Tags() ([]TagRef, error) {
+	return nil, errors.New("tags listed without the parsed tags")
+}
+
+func (s parsedOnlySource) ParsedTags() ([]*Tag, error) {
+	refs, err := s.MemoryTagSource.Tags()
+	return ParseTagRefs(refs), err
+}
+
+func TestLineTagSourceParsedTags(t *testing.T) {
+	src := parsedOnlySource{NewMemoryTagSource("c1")}
+	for _, name := range []string{"v1.2.3", "v1.2.4-test.02", "v1.5.0"} {
+		if err := src.CreateTag(name, "c0", nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	line := NewLineTagSource(src, &Line{Major: 1, Minor: 2})
+	highest, err := FindHighestVersionTag(line, "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if highest.String() != "v1.2.4-test.02" || highest.Hash != "c0" {
+		t.Errorf("highest on the line got %s at %q", highest, highest.Hash)
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PromoteRelease is the final step of the promotion order.
+const PromoteRelease = "release"
+
+// PromotionOrder is the sequence a build moves through on a single commit.
+var PromotionOrder = []string{"test", "uat", PromoteRelease}
+
+func promotionIndex(name string) int {
+	for i, n := range PromotionOrder {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Promote moves an environment tag to a later step in PromotionOrder without
+// changing its version. An empty to selects the step after the current one.
+// Promoting to a later environment keeps its counter, so v1.0.0-test.03
+// becomes v1.0.0-uat.03, while promoting to release drops the stage and
+// environment, so v1.0.0-rc.01.uat.03 becomes v1.0.0.
+func (t *Tag) Promote(to string) error {
+	from, value := EnvInfo(t)
+	if value == nil {
+		return fmt.Errorf("%s is not an environment tag", t)
+	}
+	fromIdx := promotionIndex(from)
+	to = strings.ToLower(to)
+	if to == "" {
+		if fromIdx+1 >= len(PromotionOrder) {
+			return fmt.Errorf("%s has nowhere to be promoted to", t)
+		}
+		to = PromotionOrder[fromIdx+1]
+	}
+	toIdx := promotionIndex(to)
+	if toIdx < 0 {
+		return fmt.Errorf("unknown promotion target %q; expected one of %s", to, strings.Join(PromotionOrder, ", "))
+	}
+	if toIdx <= fromIdx {
+		return fmt.Errorf("cannot promote %s from %s back to %s", t, from, to)
+	}
+
+	n := *value
+	t.Test = nil
+	t.Uat = nil
+	t.Release = nil
+	switch to {
+	case PromoteRelease:
+		t.Stage = nil
+		t.StageName = ""
+		t.StagePad = 0
+		t.Pad = 0
+	case "uat":
+		t.Uat = &n
+	}
+	return nil
+}
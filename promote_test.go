@@ -0,0 +1,48 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import "testing"
+
+func TestPromote(t *testing.T) {
+	cases := []struct {
+		start   string
+		to      string
+		want    string
+		wantErr bool
+	}{
+		{"v1.0.0-test.03", "", "v1.0.0-uat.03", false},
+		{"v1.0.0-test.03", "uat", "v1.0.0-uat.03", false},
+		{"v1.0.0-test.03", "release", "v1.0.0", false},
+		{"v1.0.0-uat.03", "", "v1.0.0", false},
+		{"v1.0.0-rc.01.uat.03", "release", "v1.0.0", false},
+		{"v1.0.0-uat3", "", "v1.0.0", false},
+		{"v1.0.0-test.03.2", "", "v1.0.0-uat.03", false},
+		{"v1.0.0-uat.03", "test", "", true},
+		{"v1.0.0-uat.03", "uat", "", true},
+		{"v1.0.0-test.03", "prod", "", true},
+		{"v1.0.0", "", "", true},
+		{"v1.0.0-rc.01", "release", "", true},
+	}
+	for _, tt := range cases {
+		tag := ParseTag(tt.start)
+		err := tag.Promote(tt.to)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Promote(%s, %q) expected error, got %s", tt.start, tt.to, tag)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Promote(%s, %q) unexpected error: %v", tt.start, tt.to, err)
+			continue
+		}
+		if got := tag.String(); got != tt.want {
+			t.Errorf("Promote(%s, %q) got %s want %s", tt.start, tt.to, got, tt.want)
+		}
+	}
+}
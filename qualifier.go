@@ -0,0 +1,183 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Qualifier is a version component a repository configures for itself, such
+// as hotfix in v1.4.2-hotfix.2 or sec in v1.4.2-sec.1.uat.3. It comes after
+// the stage and before the environment, has its own counter and is applied
+// with a command of the same name.
+type Qualifier struct {
+	// Name is both the command and the text in the tag, e.g. "hotfix".
+	Name string `json:"name"`
+	// Rank orders the qualifier against a tag without one, which ranks 0,
+	// and against other qualifiers. A positive rank sorts after the plain
+	// version, so v1.4.2-hotfix.1 follows v1.4.2; a negative one sorts
+	// before it, the way a stage does.
+	Rank int `json:"rank"`
+	// ResetBy lists the commands that drop the qualifier, from major, minor,
+	// patch and stage. Nil means all of them; commands left out keep the
+	// qualifier and its counter.
+	ResetBy []string `json:"reset_by,omitempty"`
+}
+
+// qualifierResetCommands are the commands a Qualifier may be reset by.
+var qualifierResetCommands = []string{"major", "minor", "patch", "stage"}
+
+// resetBy reports whether the command drops q.
+func (q Qualifier) resetBy(command string) bool {
+	if q.ResetBy == nil {
+		return true
+	}
+	for _, c := range q.ResetBy {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+var qualifierNameRe = regexp.MustCompile(`^[a-z]+$`)
+
+// ValidateQualifiers checks that qualifiers have lower case names that are
+// not already commands, distinct non-zero ranks and known reset commands.
+func ValidateQualifiers(qs []Qualifier) error {
+	names := map[string]bool{}
+	ranks := map[int]string{}
+	for _, q := range qs {
+		if !qualifierNameRe.MatchString(q.Name) {
+			return fmt.Errorf("qualifier name %q must be lower case letters", q.Name)
+		}
+		for _, c := range commandNames {
+			if c == q.Name {
+				return fmt.Errorf("qualifier %q is already a command", q.Name)
+			}
+		}
+		if names[q.Name] {
+			return fmt.Errorf("qualifier %q is declared twice", q.Name)
+		}
+		names[q.Name] = true
+		if q.Rank == 0 {
+			return fmt.Errorf("qualifier %q needs a non-zero rank", q.Name)
+		}
+		if other, ok := ranks[q.Rank]; ok {
+			return fmt.Errorf("qualifiers %q and %q have the same rank %d", other, q.Name, q.Rank)
+		}
+		ranks[q.Rank] = q.Name
+		for _, c := range q.ResetBy {
+			known := false
+			for _, r := range qualifierResetCommands {
+				known = known || r == c
+			}
+			if !known {
+				return fmt.Errorf("qualifier %q cannot be reset by %q; expected one of %s", q.Name, c, strings.Join(qualifierResetCommands, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+// Parser reads tags and commands, recognising a set of qualifiers besides
+// the built in components. A nil *Parser knows no qualifiers, which is how
+// ParseTag and CommandsToFlags read them.
+type Parser struct {
+	qualifiers []Qualifier
+	re         *regexp.Regexp
+}
+
+// defaultTagRe is the tag pattern without qualifiers.
+var defaultTagRe = buildParseTagRe(nil)
+
+// NewParser returns a parser for the given qualifiers, which must pass
+// ValidateQualifiers.
+func NewParser(qs []Qualifier) (*Parser, error) {
+	if err := ValidateQualifiers(qs); err != nil {
+		return nil, err
+	}
+	return &Parser{qualifiers: append([]Qualifier(nil), qs...), re: buildParseTagRe(qs)}, nil
+}
+
+// Qualifiers returns the qualifiers p recognises.
+func (p *Parser) Qualifiers() []Qualifier {
+	if p == nil {
+		return nil
+	}
+	return append([]Qualifier(nil), p.qualifiers...)
+}
+
+func (p *Parser) tagRe() *regexp.Regexp {
+	if p == nil || p.re == nil {
+		return defaultTagRe
+	}
+	return p.re
+}
+
+func (p *Parser) lookup(name string) (Qualifier, bool) {
+	if p == nil {
+		return Qualifier{}, false
+	}
+	return findQualifier(p.qualifiers, name)
+}
+
+// key identifies the qualifiers, so caches of parsed tags can tell when they
+// were parsed differently.
+func (p *Parser) key() string {
+	var parts []string
+	for _, q := range p.Qualifiers() {
+		parts = append(parts, fmt.Sprintf("%s:%d:%s", q.Name, q.Rank, strings.Join(q.ResetBy, ",")))
+	}
+	return strings.Join(parts, ";")
+}
+
+func findQualifier(qs []Qualifier, name string) (Qualifier, bool) {
+	for _, q := range qs {
+		if q.Name == name {
+			return q, true
+		}
+	}
+	return Qualifier{}, false
+}
+
+// QualifiedTagSource is implemented by tag sources that parse their tags
+// with a Parser, so Bump reads the commands with the same qualifiers.
+type QualifiedTagSource interface {
+	TagParser() *Parser
+}
+
+// SourceParser returns the parser of src, or nil when it has none.
+func SourceParser(src TagSource) *Parser {
+	if qs, ok := src.(QualifiedTagSource); ok {
+		return qs.TagParser()
+	}
+	return nil
+}
+
+// buildParseTagRe builds the tag pattern with a group for qualifiers, which
+// matches nothing when there are none.
+func buildParseTagRe(qs []Qualifier) *regexp.Regexp {
+	names := make([]string, 0, len(qs))
+	for _, q := range qs {
+		names = append(names, regexp.QuoteMeta(q.Name))
+	}
+	// longest first so one name cannot cut another short
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+	qualifier := ""
+	if len(names) > 0 {
+		qualifier = `(?:(?:-|\.)(?P<qualifier>` + strings.Join(names, "|") + `)(?:-|\.?)(?P<qualifierPadded>0*(?P<qualifierNum>\d+)))?`
+	}
+	return regexp.MustCompile(`^v(?P<major>\d+)\.(?P<minor>\d+)\.(?P<patch>\d+)` +
+		`(?:(?:-|\.)(?P<stage>alpha|beta|rc|next)(?:-|\.?)(?P<stagePadded>0*(?P<stageNum>\d+)))?` +
+		qualifier +
+		`(?:(?:-|\.)(?P<env>test|uat)(?:-|\.?)(?P<envPadded>0*(?P<envNum>\d+)))?` +
+		`(?:(?:-|\.)(?P<release>\d+))?$`)
+}
@@ -0,0 +1,189 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func testParser(t *testing.T) *Parser {
+	t.Helper()
+	p, err := NewParser([]Qualifier{
+		{Name: "hotfix", Rank: 1},
+		{Name: "sec", Rank: 2, ResetBy: []string{"major", "minor"}},
+		{Name: "preview", Rank: -1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestQualifierParseAndString(t *testing.T) {
+	p := testParser(t)
+	for _, tt := range []struct {
+		tag       string
+		qualifier string
+		value     int
+	}{
+		{"v1.4.2-hotfix.2", "hotfix", 2},
+		{"v1.4.2-sec.1.uat.3", "sec", 1},
+		{"v1.4.2-hotfix2", "hotfix", 2},
+		{"v1.4.2-rc.01.hotfix.1", "hotfix", 1},
+		{"v1.4.2-beta02-preview003-test01", "preview", 3},
+	} {
+		got := p.ParseTag(tt.tag)
+		if got == nil || got.QualifierName != tt.qualifier || got.Qualifier == nil || *got.Qualifier != tt.value {
+			t.Errorf("ParseTag(%s) = %#v", tt.tag, got)
+			continue
+		}
+		if got.String() != tt.tag {
+			t.Errorf("ParseTag(%s).String() = %s", tt.tag, got)
+		}
+	}
+	if p.ParseTag("v1.4.2-other.1") != nil {
+		t.Errorf("unconfigured qualifier parsed")
+	}
+	if ParseTag("v1.4.2-hotfix.2") != nil {
+		t.Errorf("qualifier parsed without a parser")
+	}
+	other, err := NewParser([]Qualifier{{Name: "hotfix", Rank: -1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := other.ParseTag("v1.4.2-hotfix.2"); got == nil || got.QualifierRank != -1 {
+		t.Errorf("second parser got %#v", got)
+	}
+	if got := p.ParseTag("v1.4.2-hotfix.2"); got == nil || got.QualifierRank != 1 {
+		t.Errorf("first parser changed by the second, got %#v", got)
+	}
+}
+
+func TestQualifierOrder(t *testing.T) {
+	p := testParser(t)
+	order := []string{
+		"v1.4.2",
+		"v1.4.2-hotfix.1",
+		"v1.4.2-hotfix.2.test.01",
+		"v1.4.2-hotfix.2.uat.01",
+		"v1.4.2-hotfix.2",
+		"v1.4.2-sec.1",
+		"v1.4.3-rc.01",
+		"v1.4.3-preview.1",
+		"v1.4.3",
+	}
+	for i := 0; i+1 < len(order); i++ {
+		a, b := p.ParseTag(order[i]), p.ParseTag(order[i+1])
+		if !a.LessThan(b) || b.LessThan(a) {
+			t.Errorf("expected %s < %s", a, b)
+		}
+	}
+}
+
+func TestQualifierIncrement(t *testing.T) {
+	p := testParser(t)
+	tests := []struct {
+		tag     string
+		cmds    []string
+		want    string
+		wantErr bool
+	}{
+		{tag: "v1.4.2-1", cmds: []string{"hotfix"}, want: "v1.4.2-hotfix1"},
+		{tag: "v1.4.2.1", cmds: []string{"hotfix"}, want: "v1.4.2-hotfix.1"},
+		{tag: "v1.4.2-hotfix.1", cmds: []string{"hotfix"}, want: "v1.4.2-hotfix.2"},
+		{tag: "v1.4.2-hotfix.2", cmds: []string{"uat"}, want: "v1.4.2-hotfix.2.uat.01"},
+		{tag: "v1.4.2-hotfix.2.uat.01", cmds: []string{"hotfix"}, want: "v1.4.2-hotfix.3"},
+		{tag: "v1.4.2-hotfix.2.uat.01", cmds: []string{"patch"}, want: "v1.4.2-hotfix.2"},
+		{tag: "v1.4.2-hotfix.2", cmds: []string{"patch"}, want: "v1.4.3"},
+		{tag: "v1.4.2-hotfix.2", cmds: []string{"rc"}, want: "v1.4.3-rc.01"},
+		{tag: "v1.4.2-sec.1", cmds: []string{"patch"}, want: "v1.4.3-sec.1"},
+		{tag: "v1.4.2-sec.1", cmds: []string{"minor"}, want: "v1.5.0"},
+		{tag: "v1.4.2-hotfix.3", cmds: []string{"sec"}, want: "v1.4.2-sec.1"},
+		{tag: "v1.4.2-sec.1", cmds: []string{"hotfix"}, want: "v1.4.3-hotfix.1"},
+		{tag: "v1.4.2.1", cmds: []string{"preview"}, want: "v1.4.3-preview.1"},
+		{tag: "v1.4.3-preview.1", cmds: []string{"patch"}, want: "v1.4.3"},
+		{tag: "v1.4.2-hotfix.1", cmds: []string{"hotfix03", "test"}, want: "v1.4.2-hotfix.03.test.01"},
+		{tag: "v1.4.2-hotfix.2", cmds: []string{"hotfix1"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag+" "+strings.Join(tt.cmds, " "), func(t *testing.T) {
+			tag := p.ParseTag(tt.tag)
+			if tag == nil {
+				t.Fatalf("cannot parse %s", tt.tag)
+			}
+			flags := p.CommandsToFlags(tt.cmds, "auto")
+			if !flags.Valid {
+				t.Fatalf("invalid commands %v", tt.cmds)
+			}
+			err := tag.IncrementWithOptions(flags, IncrementOptions{Qualifiers: p.Qualifiers()})
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %s", tag)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tag.String() != tt.want {
+				t.Errorf("got %s, want %s", tag, tt.want)
+			}
+		})
+	}
+}
+
+func TestQualifierCommands(t *testing.T) {
+	p := testParser(t)
+	if got := strings.Join(p.Commands("auto"), " "); !strings.HasSuffix(got, "hotfix sec preview") {
+		t.Errorf("commands %q do not end with the qualifiers", got)
+	}
+	if p.CommandsToFlags([]string{"hotfix", "sec"}, "auto").Valid {
+		t.Errorf("two qualifiers were accepted")
+	}
+	if got := p.CommandsToFlags([]string{"patch", "hotfix2", "uat"}, "auto").String(); got != "patch hotfix2 uat" {
+		t.Errorf("got %q", got)
+	}
+	if CommandsToFlags([]string{"hotfix"}, "auto").Valid {
+		t.Errorf("qualifier accepted without a parser")
+	}
+	tag := p.ParseTag("v1.4.2")
+	if err := tag.IncrementWithOptions(p.CommandsToFlags([]string{"hotfix"}, "auto"), IncrementOptions{}); err == nil {
+		t.Errorf("qualifier applied without its definition, got %s", tag)
+	}
+}
+
+func TestQualifierBump(t *testing.T) {
+	src := NewMemoryTagSource("c2")
+	src.Parser = testParser(t)
+	if err := src.CreateTag("v1.4.2-hotfix.1", "c1", nil); err != nil {
+		t.Fatal(err)
+	}
+	res, err := Bump(context.Background(), src, BumpOptions{Commands: []string{"hotfix"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag.String() != "v1.4.2-hotfix.2" {
+		t.Errorf("got %s, want v1.4.2-hotfix.2", res.Tag)
+	}
+}
+
+func TestValidateQualifiers(t *testing.T) {
+	for _, qs := range [][]Qualifier{
+		{{Name: "rc", Rank: 1}},
+		{{Name: "Hotfix", Rank: 1}},
+		{{Name: "hotfix", Rank: 0}},
+		{{Name: "hotfix", Rank: 1}, {Name: "sec", Rank: 1}},
+		{{Name: "hotfix", Rank: 1}, {Name: "hotfix", Rank: 2}},
+		{{Name: "hotfix", Rank: 1, ResetBy: []string{"test"}}},
+	} {
+		if err := ValidateQualifiers(qs); err == nil {
+			t.Errorf("%v: expected an error", qs)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// DefaultReleaseAPI is the API base URL used when none is configured.
+// GitHub Enterprise lives at https://host/api/v3 and Gitea at
+// https://host/api/v1.
+const DefaultReleaseAPI = "https://api.github.com"
+
+// DefaultReleaseTokenEnv is the environment variable the API token is read
+// from when none is configured.
+const DefaultReleaseTokenEnv = "GITHUB_TOKEN"
+
+// ReleaseConfig is the "release" object of ConfigFile. Empty fields fall
+// back to the defaults, and Owner and Repo to the origin remote.
+type ReleaseConfig struct {
+	APIURL   string `json:"api_url,omitempty"`
+	Owner    string `json:"owner,omitempty"`
+	Repo     string `json:"repo,omitempty"`
+	TokenEnv string `json:"token_env,omitempty"`
+}
+
+// ReleaseRequest is the body of a create release call, as GitHub and Gitea
+// both accept it.
+type ReleaseRequest struct {
+	TagName         string `json:"tag_name"`
+	TargetCommitish string `json:"target_commitish,omitempty"`
+	Name            string `json:"name"`
+	Body            string `json:"body"`
+	Draft           bool   `json:"draft"`
+	Prerelease      bool   `json:"prerelease"`
+}
+
+// Release is what the API returns for a created release.
+type Release struct {
+	ID      int64  `json:"id"`
+	HTMLURL string `json:"html_url"`
+}
+
+// IsPrerelease reports whether t has a stage, an environment or a qualifier
+// ranked below the release.
+func IsPrerelease(t *Tag) bool {
+	_, env := EnvInfo(t)
+	return t.Stage != nil || env != nil || t.QualifierRank < 0
+}
+
+// NewReleaseRequest describes the release of t at the target commit, marked
+// as a pre-release when IsPrerelease says so.
+func NewReleaseRequest(t *Tag, target, notes string) ReleaseRequest {
+	return ReleaseRequest{
+		TagName:         t.String(),
+		TargetCommitish: target,
+		Name:            t.String(),
+		Body:            notes,
+		Prerelease:      IsPrerelease(t),
+	}
+}
+
+// ReleaseNotes lists the commit subjects between previous and t. previous
+// may be nil when t is the first tag.
+func ReleaseNotes(previous, t *Tag, subjects []string) string {
+	var b strings.Builder
+	if previous == nil || previous.Hash == "" {
+		fmt.Fprintf(&b, "## %s\n\n", t)
+	} else {
+		fmt.Fprintf(&b, "## Changes since %s\n\n", previous)
+	}
+	if len(subjects) == 0 {
+		b.WriteString("No changes.\n")
+	}
+	for _, s := range subjects {
+		fmt.Fprintf(&b, "- %s\n", s)
+	}
+	return b.String()
+}
+
+// ReleaseError is returned by ReleaseClient.Create when the API refuses the
+// release.
+type ReleaseError struct {
+	Status  int
+	Message string
+}
+
+func (e *ReleaseError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("release API returned %d %s", e.Status, http.StatusText(e.Status))
+	}
+	return fmt.Sprintf("release API returned %d %s: %s", e.Status, http.StatusText(e.Status), e.Message)
+}
+
+// ReleaseClient creates releases through the GitHub compatible API at
+// BaseURL.
+type ReleaseClient struct {
+	BaseURL string
+	Owner   string
+	Repo    string
+	Token   string
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// Create posts req to {BaseURL}/repos/{Owner}/{Repo}/releases.
+func (c *ReleaseClient) Create(ctx context.Context, req ReleaseRequest) (*Release, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := strings.TrimRight(c.BaseURL, "/") + "/repos/" + url.PathEscape(c.Owner) + "/" + url.PathEscape(c.Repo) + "/releases"
+	hr, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	hr.Header.Set("Content-Type", "application/json")
+	hr.Header.Set("Accept", "application/vnd.github+json, application/json")
+	if c.Token != "" {
+		hr.Header.Set("Authorization", "token "+c.Token)
+	}
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(hr)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(b, &apiErr)
+		return nil, &ReleaseError{Status: resp.StatusCode, Message: apiErr.Message}
+	}
+	rel := &Release{}
+	if err := json.Unmarshal(b, rel); err != nil {
+		return nil, fmt.Errorf("decoding release: %w", err)
+	}
+	return rel, nil
+}
+
+var remoteRepoRe = regexp.MustCompile(`[:/]([^/:]+)/([^/]+?)(?:\.git)?/?$`)
+
+// ParseRemoteRepo returns the owner and repository named by a remote URL
+// such as https://github.com/arran4/git-tag-inc.git or
+// git@github.com:arran4/git-tag-inc.git.
+func ParseRemoteRepo(remote string) (owner, repo string, ok bool) {
+	m := remoteRepoRe.FindStringSubmatch(remote)
+	if m == nil {
+		return "", "", false
+	}
+	return m[1], m[2], true
+}
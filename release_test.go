@@ -0,0 +1,84 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestReleaseClient(t *testing.T) {
+	var got ReleaseRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/repos/arran4/git-tag-inc/releases" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		if auth := r.Header.Get("Authorization"); auth != "token secret" {
+			t.Errorf("unexpected authorization %q", auth)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Error(err)
+		}
+		if got.TagName == "v1.0.0" {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message": "Validation Failed"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"id": 7, "html_url": "https://example.com/releases/7"}`))
+	}))
+	defer srv.Close()
+
+	c := &ReleaseClient{BaseURL: srv.URL + "/api/v1/", Owner: "arran4", Repo: "git-tag-inc", Token: "secret"}
+	previous := ParseTag("v1.2.3")
+	previous.Hash = "abc"
+	tag := ParseTag("v1.2.4-rc.01")
+	rel, err := c.Create(context.Background(), NewReleaseRequest(tag, "c1", ReleaseNotes(previous, tag, []string{"1234567 Fix it"})))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rel.ID != 7 || rel.HTMLURL != "https://example.com/releases/7" {
+		t.Errorf("unexpected release %+v", rel)
+	}
+	want := ReleaseRequest{
+		TagName:         "v1.2.4-rc.01",
+		TargetCommitish: "c1",
+		Name:            "v1.2.4-rc.01",
+		Body:            "## Changes since v1.2.3\n\n- 1234567 Fix it\n",
+		Prerelease:      true,
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	_, err = c.Create(context.Background(), NewReleaseRequest(ParseTag("v1.0.0"), "c1", ""))
+	var re *ReleaseError
+	if !errors.As(err, &re) || re.Status != http.StatusUnprocessableEntity || re.Message != "Validation Failed" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestParseRemoteRepo(t *testing.T) {
+	for _, remote := range []string{
+		"https://github.com/arran4/git-tag-inc.git",
+		"https://gitea.example.com/arran4/git-tag-inc",
+		"git@github.com:arran4/git-tag-inc.git",
+		"ssh://git@host:2222/arran4/git-tag-inc.git",
+	} {
+		owner, repo, ok := ParseRemoteRepo(remote)
+		if !ok || owner != "arran4" || repo != "git-tag-inc" {
+			t.Errorf("%s: got %q %q %v", remote, owner, repo, ok)
+		}
+	}
+	if _, _, ok := ParseRemoteRepo("git-tag-inc"); ok {
+		t.Errorf("expected a bare name not to parse")
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+)
+
+// SignOffError is returned by CheckSignOff when the target commit lacks a
+// tag of the environment that must come first.
+type SignOffError struct {
+	Tag      *Tag
+	Required string
+	Target   string
+}
+
+func (e *SignOffError) Error() string {
+	return fmt.Sprintf("%s needs a %s tag for v%d.%d.%d on commit %s first", e.Tag, e.Required, e.Tag.Major, e.Tag.Minor, e.Tag.Patch, e.Target)
+}
+
+// signOffStep returns t's place in PromotionOrder: its environment, or
+// release when it has neither an environment nor a stage. Pre-releases
+// without an environment give "".
+func signOffStep(t *Tag) string {
+	if env, value := EnvInfo(t); value != nil {
+		return env
+	}
+	if t.Stage == nil {
+		return PromoteRelease
+	}
+	return ""
+}
+
+// signsOff reports whether have, an existing tag of environment required,
+// signs off on t. Both must share the base version and qualifier; an
+// environment tag must also share the stage, while a release accepts any
+// stage it came from.
+func signsOff(have, t *Tag, required string) bool {
+	if env, value := EnvInfo(have); value == nil || env != required {
+		return false
+	}
+	if have.Major != t.Major || have.Minor != t.Minor || have.Patch != t.Patch {
+		return false
+	}
+	if have.QualifierName != t.QualifierName || (have.Qualifier != nil && *have.Qualifier != *t.Qualifier) {
+		return false
+	}
+	if signOffStep(t) == PromoteRelease {
+		return true
+	}
+	if (have.Stage == nil) != (t.Stage == nil) {
+		return false
+	}
+	return t.Stage == nil || (have.StageName == t.StageName && *have.Stage == *t.Stage)
+}
+
+// CheckSignOff enforces PromotionOrder across commits: before t is created
+// on target, target must carry a tag of the environment preceding t's, so a
+// release needs a uat tag and a uat tag needs a test tag, each for the same
+// base version. Tags are resolved to their commits, so annotated and nested
+// tags count. Tags with nothing before them in the order always pass.
+func CheckSignOff(src TagSource, t *Tag, target string) error {
+	idx := promotionIndex(signOffStep(t))
+	if idx <= 0 {
+		return nil
+	}
+	required := PromotionOrder[idx-1]
+	refs, err := src.Tags()
+	if err != nil {
+		return err
+	}
+	p := SourceParser(src)
+	for _, ref := range refs {
+		have := p.ParseTag(ref.Name)
+		if have == nil || !signsOff(have, t, required) {
+			continue
+		}
+		h, err := src.ResolveTag(ref.Name)
+		if err != nil {
+			return fmt.Errorf("resolving %s: %w", ref.Name, err)
+		}
+		if h != "" && h == target {
+			return nil
+		}
+	}
+	return &SignOffError{Tag: t.Clone(), Required: required, Target: target}
+}
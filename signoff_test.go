@@ -0,0 +1,73 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCheckSignOff(t *testing.T) {
+	src := NewMemoryTagSource("c3")
+	for name, commit := range map[string]string{
+		"v1.0.0-test.01":       "c1",
+		"v1.0.0-test.02":       "c2",
+		"v1.0.0-uat.02":        "c2",
+		"v1.1.0-rc.01.test.01": "c3",
+		"v1.1.0-rc.01.uat.01":  "c4",
+	} {
+		if err := src.CreateTag(name, commit, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		tag      string
+		target   string
+		required string
+	}{
+		{"v1.0.0-test.03", "c9", ""},
+		{"v1.0.0-uat.01", "c1", ""},
+		{"v1.0.0-uat.03", "c9", "test"},
+		{"v1.0.1-uat.01", "c1", "test"},
+		{"v1.0.0", "c2", ""},
+		{"v1.0.0", "c1", "uat"},
+		{"v1.0.0-rc.01", "c9", ""},
+		{"v1.1.0-rc.01.uat.02", "c3", ""},
+		{"v1.1.0-rc.02.uat.01", "c3", "test"},
+		{"v1.1.0-uat.01", "c3", "test"},
+		{"v1.1.0", "c4", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag+" on "+tt.target, func(t *testing.T) {
+			err := CheckSignOff(src, ParseTag(tt.tag), tt.target)
+			if tt.required == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			var se *SignOffError
+			if !errors.As(err, &se) || se.Required != tt.required {
+				t.Errorf("expected a missing %s sign-off, got %v", tt.required, err)
+			}
+		})
+	}
+
+	_, err := Bump(context.Background(), src, BumpOptions{Commands: []string{"patch"}, RequireSignOff: true, Dry: true})
+	if code := ErrorCode(err); code != ErrCodeSignOff {
+		t.Errorf("expected %s, got %q (%v)", ErrCodeSignOff, code, err)
+	}
+	src.SetHead("c4")
+	res, err := Bump(context.Background(), src, BumpOptions{Commands: []string{"patch"}, RequireSignOff: true, Dry: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag.String() != "v1.1.0" {
+		t.Errorf("got %s", res.Tag)
+	}
+}
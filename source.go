@@ -0,0 +1,279 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrTagExists   = errors.New("tag already exists")
+)
+
+// TagRef is a tag as listed by a TagSource. Hash is the hash the reference
+// points at, which is the tag object for annotated tags.
+type TagRef struct {
+	Name string
+	Hash string
+}
+
+// Signature identifies who created an annotated tag.
+type Signature struct {
+	Name  string
+	Email string
+	When  time.Time
+}
+
+// CreateTagOptions describes an annotated tag. Passing nil options to
+// TagSource.CreateTag creates a lightweight tag.
+type CreateTagOptions struct {
+	Message string
+	Tagger  *Signature
+}
+
+// TagSource is where tags are read from and created. It lets the version
+// logic run against a go-git repository, an in-memory set of tags in tests,
+// or any other backend.
+type TagSource interface {
+	// Tags lists every tag in the source.
+	Tags() ([]TagRef, error)
+	// ResolveTag returns the commit a tag points at, or ErrTagNotFound.
+	ResolveTag(name string) (string, error)
+	// Head returns the commit HEAD points at.
+	Head() (string, error)
+	// CreateTag tags the target commit, or returns ErrTagExists.
+	CreateTag(name, target string, opts *CreateTagOptions) error
+}
+
+// ParsedTagSource is implemented by tag sources that already hold parsed
+// version tags, such as CachedTagSource, so VersionTags can skip parsing.
+type ParsedTagSource interface {
+	// ParsedTags returns the version tags with Hash set to the reference
+	// hash. Callers may modify the returned tags.
+	ParsedTags() ([]*Tag, error)
+}
+
+// VersionTags parses every tag in the source, skipping names that are not
+// version tags. Unless mode is "auto" it overrides each tag's Mode.
+func VersionTags(src TagSource, mode string) ([]*Tag, error) {
+	var tags []*Tag
+	if ps, ok := src.(ParsedTagSource); ok {
+		var err error
+		if tags, err = ps.ParsedTags(); err != nil {
+			return nil, err
+		}
+	} else {
+		refs, err := src.Tags()
+		if err != nil {
+			return nil, err
+		}
+		tags = SourceParser(src).ParseTagRefs(refs)
+	}
+	if mode != "auto" {
+		for _, t := range tags {
+			t.Mode = mode
+		}
+	}
+	return tags, nil
+}
+
+// ParseTagRefs parses refs without qualifiers, see Parser.ParseTagRefs.
+func ParseTagRefs(refs []TagRef) []*Tag {
+	return (*Parser)(nil).ParseTagRefs(refs)
+}
+
+// ParseTagRefs parses refs as version tags with Hash set to the reference
+// hash, skipping names that are not version tags. It lets a wrapping
+// ParsedTagSource parse the tags it lists itself.
+func (p *Parser) ParseTagRefs(refs []TagRef) []*Tag {
+	tags := make([]*Tag, 0, len(refs))
+	for _, ref := range refs {
+		t := p.ParseTag(ref.Name)
+		if t == nil {
+			continue
+		}
+		t.Hash = ref.Hash
+		tags = append(tags, t)
+	}
+	return tags
+}
+
+// FindHVersionTag walks the version tags in src and keeps the current tag
+// whenever stop reports that it should replace the best so far. The search
+// starts from an empty v0.0.0 in mode, or semver when mode is "auto".
+func FindHVersionTag(src TagSource, mode string, stop func(last, current *Tag) bool) (*Tag, error) {
+	tags, err := VersionTags(src, mode)
+	if err != nil {
+		return nil, err
+	}
+	return pickVersionTag(tags, mode, stop), nil
+}
+
+func pickVersionTag(tags []*Tag, mode string, stop func(last, current *Tag) bool) *Tag {
+	startMode := mode
+	if mode == "auto" {
+		startMode = ModeSemver
+	}
+	var highest *Tag = &Tag{Mode: startMode}
+	for _, t := range tags {
+		if stop(highest, t) {
+			highest = t
+		}
+	}
+	return highest
+}
+
+// FindHighestVersionTag returns the highest version tag in src.
+func FindHighestVersionTag(src TagSource, mode string) (*Tag, error) {
+	return ExplainHighestVersionTag(src, mode, nil)
+}
+
+// ExplainHighestVersionTag is FindHighestVersionTag, also writing to w, when
+// it is not nil, why each other tag is not the highest.
+func ExplainHighestVersionTag(src TagSource, mode string, w io.Writer) (*Tag, error) {
+	tags, err := VersionTags(src, mode)
+	if err != nil {
+		return nil, err
+	}
+	highest := pickVersionTag(tags, mode, func(last, current *Tag) bool {
+		return last.LessThan(current)
+	})
+	explainRanking(w, tags, highest)
+	return highest, nil
+}
+
+// FindHighestSimilarVersionTag returns the highest tag for the environment
+// env, or the highest tag without an environment when env is empty.
+func FindHighestSimilarVersionTag(src TagSource, mode, env string) (*Tag, error) {
+	return FindHVersionTag(src, mode, func(last, current *Tag) bool {
+		if env == "test" && current.Test == nil {
+			return false
+		}
+		if env == "uat" && current.Uat == nil {
+			return false
+		}
+		if env == "" && (current.Uat != nil || current.Test != nil) {
+			return false
+		}
+		return last.LessThan(current)
+	})
+}
+
+// TagPeeler is implemented by tag sources whose TagRef hashes can be tag
+// objects rather than commits, such as GoGitTagSource.
+type TagPeeler interface {
+	// PeelTag returns the commit at the end of a TagRef hash, or "" when it
+	// does not end at a commit.
+	PeelTag(hash string) (string, error)
+}
+
+// PeelTag returns the commit a TagRef hash listed by src points at. Sources
+// that do not implement TagPeeler list commits, which are returned as is.
+func PeelTag(src TagSource, hash string) (string, error) {
+	if p, ok := src.(TagPeeler); ok {
+		return p.PeelTag(hash)
+	}
+	return hash, nil
+}
+
+// GetHash returns the commit a version tag points at, or HEAD's commit when
+// t is nil. A tag found in src carries the hash it was listed with, which is
+// peeled, as its name may not round trip through String. Otherwise the tag
+// is looked up by name, and one that does not exist gives an empty hash.
+func GetHash(src TagSource, t *Tag) (string, error) {
+	if t == nil {
+		return src.Head()
+	}
+	if t.Hash != "" {
+		return PeelTag(src, t.Hash)
+	}
+	h, err := src.ResolveTag(t.String())
+	if errors.Is(err, ErrTagNotFound) {
+		return "", nil
+	}
+	return h, err
+}
+
+type memoryTag struct {
+	commit string
+	opts   *CreateTagOptions
+}
+
+// MemoryTagSource is a TagSource held entirely in memory, mainly for tests.
+type MemoryTagSource struct {
+	// Parser reads the tags, nil for no qualifiers.
+	Parser *Parser
+
+	mu    sync.Mutex
+	head  string
+	names []string
+	tags  map[string]memoryTag
+}
+
+var (
+	_ TagSource          = (*MemoryTagSource)(nil)
+	_ QualifiedTagSource = (*MemoryTagSource)(nil)
+)
+
+// NewMemoryTagSource returns an empty source whose HEAD is at head.
+func NewMemoryTagSource(head string) *MemoryTagSource {
+	return &MemoryTagSource{head: head, tags: map[string]memoryTag{}}
+}
+
+func (m *MemoryTagSource) TagParser() *Parser {
+	return m.Parser
+}
+
+// SetHead moves HEAD to another commit.
+func (m *MemoryTagSource) SetHead(head string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.head = head
+}
+
+// Tags lists the tags in the order they were created.
+func (m *MemoryTagSource) Tags() ([]TagRef, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	refs := make([]TagRef, 0, len(m.names))
+	for _, name := range m.names {
+		refs = append(refs, TagRef{Name: name, Hash: m.tags[name].commit})
+	}
+	return refs, nil
+}
+
+func (m *MemoryTagSource) ResolveTag(name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	t, ok := m.tags[name]
+	if !ok {
+		return "", ErrTagNotFound
+	}
+	return t.commit, nil
+}
+
+func (m *MemoryTagSource) Head() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.head, nil
+}
+
+func (m *MemoryTagSource) CreateTag(name, target string, opts *CreateTagOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.tags[name]; ok {
+		return fmt.Errorf("%s: %w", name, ErrTagExists)
+	}
+	m.names = append(m.names, name)
+	m.tags[name] = memoryTag{commit: target, opts: opts}
+	return nil
+}
@@ -0,0 +1,259 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/storage/memory"
+)
+
+func TestFindVersionTags(t *testing.T) {
+	src := NewMemoryTagSource("c3")
+	for name, commit := range map[string]string{
+		"v1.0.0-test.01": "c1",
+		"v1.0.0-test.02": "c2",
+		"v1.0.0-uat.01":  "c1",
+		"v0.9.0":         "c1",
+		"notes":          "c2",
+	} {
+		if err := src.CreateTag(name, commit, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	highest, err := FindHighestVersionTag(src, "auto")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := highest.String(); got != "v1.0.0-test.02" {
+		t.Errorf("highest got %s", got)
+	}
+
+	similar, err := FindHighestSimilarVersionTag(src, "auto", "uat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := similar.String(); got != "v1.0.0-uat.01" {
+		t.Errorf("similar uat got %s", got)
+	}
+	h, err := GetHash(src, similar)
+	if err != nil || h != "c1" {
+		t.Errorf("GetHash got %s, %v", h, err)
+	}
+
+	release, err := FindHighestSimilarVersionTag(src, ModeLegacy, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := release.String(); got != "v0.9.0" {
+		t.Errorf("similar release got %s", got)
+	}
+
+	head, err := GetHash(src, nil)
+	if err != nil || head != "c3" {
+		t.Errorf("GetHash(nil) got %s, %v", head, err)
+	}
+	missing, err := GetHash(src, ParseTag("v9.9.9"))
+	if err != nil || missing != "" {
+		t.Errorf("GetHash(missing) got %q, %v", missing, err)
+	}
+
+	if err := src.CreateTag("v0.9.0", "c3", nil); !errors.Is(err, ErrTagExists) {
+		t.Errorf("expected ErrTagExists, got %v", err)
+	}
+
+	empty, err := FindHighestVersionTag(NewMemoryTagSource(""), ModeLegacy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.String() != "v0.0.0" || empty.Mode != ModeLegacy {
+		t.Errorf("empty source got %s (%s)", empty, empty.Mode)
+	}
+}
+
+func TestGoGitTagSource(t *testing.T) {
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+	commit := &object.Commit{Author: sig, Committer: sig, Message: "one", TreeHash: plumbing.ZeroHash}
+	obj := r.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		t.Fatal(err)
+	}
+	c1, err := r.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), c1)); err != nil {
+		t.Fatal(err)
+	}
+
+	src := NewGoGitTagSource(r)
+	if err := src.CreateTag("v1.0.0", c1.String(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.CreateTag("v1.0.1", c1.String(), &CreateTagOptions{
+		Message: "v1.0.1",
+		Tagger:  &Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := src.CreateTag("v1.0.1", c1.String(), nil); !errors.Is(err, ErrTagExists) {
+		t.Errorf("expected ErrTagExists, got %v", err)
+	}
+
+	for _, name := range []string{"v1.0.0", "v1.0.1"} {
+		h, err := src.ResolveTag(name)
+		if err != nil || h != c1.String() {
+			t.Errorf("ResolveTag(%s) got %s, %v", name, h, err)
+		}
+	}
+	if _, err := src.ResolveTag("v2.0.0"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("expected ErrTagNotFound, got %v", err)
+	}
+	head, err := src.Head()
+	if err != nil || head != c1.String() {
+		t.Errorf("Head got %s, %v", head, err)
+	}
+	highest, err := FindHighestVersionTag(src, "auto")
+	if err != nil || highest.String() != "v1.0.1" {
+		t.Errorf("highest got %s, %v", highest, err)
+	}
+	// the listed hash of the annotated tag is its tag object
+	if h, err := GetHash(src, highest); err != nil || h != c1.String() {
+		t.Errorf("GetHash(%s) got %s, %v", highest, h, err)
+	}
+}
+
+func TestGoGitTagSourceLockFiles(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+	c1, err := w.Commit("one", &git.CommitOptions{Author: sig, AllowEmptyCommits: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := NewGoGitTagSource(r)
+	if err := src.CreateTag("v1.0.0", c1.String(), nil); err != nil {
+		t.Fatal(err)
+	}
+	tags := filepath.Join(dir, ".git", "refs", "tags")
+	packed := c1.String() + " refs/tags/v0.9.0\n" + c1.String() + " refs/tags/v1.0.0\n"
+	if err := os.WriteFile(filepath.Join(dir, ".git", "packed-refs"), []byte("# pack-refs with: peeled fully-peeled sorted\n"+packed), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// another process's locks, one just created and one written but not yet
+	// renamed into place
+	if err := os.WriteFile(filepath.Join(tags, "v1.0.1.lock"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tags, "v1.0.2.lock"), []byte(c1.String()+"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		refs, err := src.Tags()
+		if err != nil {
+			t.Fatalf("Tags with a lock file present: %v", err)
+		}
+		var names []string
+		for _, ref := range refs {
+			names = append(names, ref.Name)
+			if ref.Hash != c1.String() {
+				t.Errorf("%s got hash %s", ref.Name, ref.Hash)
+			}
+		}
+		if len(names) != 2 || names[0] != "v1.0.0" || names[1] != "v0.9.0" {
+			t.Errorf("Tags got %v, want [v1.0.0 v0.9.0]", names)
+		}
+	}
+}
+
+func TestPeelToCommit(t *testing.T) {
+	r, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := func(o interface {
+		Encode(plumbing.EncodedObject) error
+	}) plumbing.Hash {
+		t.Helper()
+		obj := r.Storer.NewEncodedObject()
+		if err := o.Encode(obj); err != nil {
+			t.Fatal(err)
+		}
+		h, err := r.Storer.SetEncodedObject(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return h
+	}
+	tree := store(&object.Tree{})
+	sig := object.Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0)}
+	c1 := store(&object.Commit{Author: sig, Committer: sig, Message: "one", TreeHash: tree})
+	if err := r.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName("master"), c1)); err != nil {
+		t.Fatal(err)
+	}
+	annotated := func(name string, target plumbing.Hash) plumbing.Hash {
+		t.Helper()
+		ref, err := r.CreateTag(name, target, &git.CreateTagOptions{Message: name, Tagger: &sig})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ref.Hash()
+	}
+
+	if _, err := r.CreateTag("v1.0.0", c1, nil); err != nil {
+		t.Fatal(err)
+	}
+	inner := annotated("v1.0.1", c1)
+	middle := annotated("v1.0.2", inner)
+	annotated("v1.0.3", middle)
+	annotated("v1.0.4", tree)
+
+	src := NewGoGitTagSource(r)
+	for _, name := range []string{"v1.0.0", "v1.0.1", "v1.0.2", "v1.0.3"} {
+		ref, err := r.Tag(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if h, err := PeelToCommit(r, ref.Hash()); err != nil || h != c1 {
+			t.Errorf("PeelToCommit(%s) got %s, %v", name, h, err)
+		}
+		if h, err := GetHash(src, ParseTag(name)); err != nil || h != c1.String() {
+			t.Errorf("GetHash(%s) got %s, %v", name, h, err)
+		}
+	}
+
+	ref, err := r.Tag("v1.0.4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := PeelToCommit(r, ref.Hash()); !errors.Is(err, ErrNotCommit) {
+		t.Errorf("expected ErrNotCommit for a tree tag, got %v", err)
+	}
+	if h, err := GetHash(src, ParseTag("v1.0.4")); err != nil || h != "" {
+		t.Errorf("GetHash of a tree tag got %q, %v", h, err)
+	}
+}
@@ -8,10 +8,9 @@ package gittaginc
 
 import (
 	"fmt"
-	"regexp"
+	"io"
 	"strconv"
 	"strings"
-	"sync"
 )
 
 func ptr(i int) *int {
@@ -26,6 +25,13 @@ type Tag struct {
 	Stage     *int
 	StagePad  int
 
+	// QualifierName is one of the parser's qualifiers, or "".
+	QualifierName string
+	Qualifier     *int
+	QualifierPad  int
+	// QualifierRank is the Rank of the qualifier, 0 without one.
+	QualifierRank int
+
 	Test *int
 	Uat  *int
 	Pad  int
@@ -41,19 +47,26 @@ func (t *Tag) Clone() *Tag {
 		return nil
 	}
 	clone := &Tag{
-		Hash:      t.Hash,
-		Mode:      t.Mode,
-		StageName: t.StageName,
-		StagePad:  t.StagePad,
-		Pad:       t.Pad,
-		Patch:     t.Patch,
-		Major:     t.Major,
-		Minor:     t.Minor,
+		Hash:          t.Hash,
+		Mode:          t.Mode,
+		StageName:     t.StageName,
+		StagePad:      t.StagePad,
+		QualifierName: t.QualifierName,
+		QualifierPad:  t.QualifierPad,
+		QualifierRank: t.QualifierRank,
+		Pad:           t.Pad,
+		Patch:         t.Patch,
+		Major:         t.Major,
+		Minor:         t.Minor,
 	}
 	if t.Stage != nil {
 		v := *t.Stage
 		clone.Stage = &v
 	}
+	if t.Qualifier != nil {
+		v := *t.Qualifier
+		clone.Qualifier = &v
+	}
 	if t.Test != nil {
 		v := *t.Test
 		clone.Test = &v
@@ -108,18 +121,26 @@ func stageRank(n string) stageRankType {
 }
 
 func (t *Tag) LessThan(other *Tag) bool {
+	less, _ := t.compare(other)
+	return less
+}
+
+// compare reports whether t is lower than other along with the component
+// that decided it, or "" when neither component decided. It is LessThan with
+// the reason kept for explanations.
+func (t *Tag) compare(other *Tag) (bool, string) {
 	if t.Major != other.Major {
-		return t.Major < other.Major
+		return t.Major < other.Major, "major"
 	}
 	if t.Minor != other.Minor {
-		return t.Minor < other.Minor
+		return t.Minor < other.Minor, "minor"
 	}
 	if t.Patch != other.Patch {
-		return t.Patch < other.Patch
+		return t.Patch < other.Patch, "patch"
 	}
 
 	if stageRank(t.StageName) != stageRank(other.StageName) {
-		return stageRank(t.StageName) < stageRank(other.StageName)
+		return stageRank(t.StageName) < stageRank(other.StageName), "stage"
 	}
 	if t.Stage != nil || other.Stage != nil {
 		tv := 0
@@ -131,9 +152,15 @@ func (t *Tag) LessThan(other *Tag) bool {
 			ov = *other.Stage
 		}
 		if tv != ov {
-			return tv < ov
+			return tv < ov, "stage counter"
 		}
 	}
+	if t.QualifierRank != other.QualifierRank {
+		return t.QualifierRank < other.QualifierRank, "qualifier"
+	}
+	if t.Qualifier != nil && other.Qualifier != nil && *t.Qualifier != *other.Qualifier {
+		return *t.Qualifier < *other.Qualifier, "qualifier counter"
+	}
 
 	var tv *int = nil
 	if t.Uat != nil {
@@ -148,17 +175,17 @@ func (t *Tag) LessThan(other *Tag) bool {
 		ov = other.Test
 	}
 	if tv == nil {
-		return false
+		return false, "environment"
 	}
 	if ov == nil {
-		return true
+		return true, "environment"
 	}
 	if *tv < *ov {
-		return true
+		return true, "environment counter"
 	}
 	if *tv == *ov {
 		if other.Uat != nil && t.Test != nil {
-			return true
+			return true, "environment"
 		}
 	}
 
@@ -171,9 +198,9 @@ func (t *Tag) LessThan(other *Tag) bool {
 		ovv = *other.Release
 	}
 	if rv != ovv {
-		return rv < ovv
+		return rv < ovv, "release"
 	}
-	return false
+	return false, ""
 }
 
 func (t *Tag) String() string {
@@ -182,6 +209,9 @@ func (t *Tag) String() string {
 		if t.Stage != nil {
 			ext += fmt.Sprintf("-%s%0*d", t.StageName, t.StagePad, *t.Stage)
 		}
+		if t.Qualifier != nil {
+			ext += fmt.Sprintf("-%s%0*d", t.QualifierName, t.QualifierPad, *t.Qualifier)
+		}
 		if t.Uat != nil {
 			ext += fmt.Sprintf("-uat%0*d", t.Pad, *t.Uat)
 		} else if t.Test != nil {
@@ -194,6 +224,14 @@ func (t *Tag) String() string {
 		if t.Stage != nil {
 			ext += fmt.Sprintf("-%s.%0*d", t.StageName, t.StagePad, *t.Stage)
 		}
+		if t.Qualifier != nil {
+			if ext == "" {
+				ext += "-"
+			} else {
+				ext += "."
+			}
+			ext += fmt.Sprintf("%s.%0*d", t.QualifierName, t.QualifierPad, *t.Qualifier)
+		}
 		if t.Uat != nil {
 			if ext == "" {
 				ext += "-uat."
@@ -220,25 +258,29 @@ func (t *Tag) String() string {
 	return fmt.Sprintf("v%d.%d.%d%s", t.Major, t.Minor, t.Patch, ext)
 }
 
-var parseTagRe *regexp.Regexp
-var parseTagOnce sync.Once
-
-func getParseTagRe() *regexp.Regexp {
-	parseTagOnce.Do(func() {
-		parseTagRe = regexp.MustCompile(`^v(\d+)\.(\d+)\.(\d+)(?:(?:-|\.)((?:alpha|beta|rc|next))(?:(?:-|\.?)((?:0*)(\d+))))?(?:(?:-|\.)((?:test|uat))(?:(?:-|\.?)((?:0*)(\d+))))?(?:(?:-|\.)(\d+))?$`)
-	})
-	return parseTagRe
+// ParseTag parses tag without qualifiers, see Parser.ParseTag.
+func ParseTag(tag string) *Tag {
+	return (*Parser)(nil).ParseTag(tag)
 }
 
-func ParseTag(tag string) *Tag {
-	m := getParseTagRe().FindStringSubmatch(tag)
+// ParseTag parses tag, recognising p's qualifiers, and returns nil when it
+// is not a version tag.
+func (p *Parser) ParseTag(tag string) *Tag {
+	re := p.tagRe()
+	m := re.FindStringSubmatch(tag)
 	t := &Tag{}
 	if len(m) == 0 {
 		return nil
 	}
-	t.Major, _ = strconv.Atoi(m[1])
-	t.Minor, _ = strconv.Atoi(m[2])
-	t.Patch, _ = strconv.Atoi(m[3])
+	group := func(name string) string {
+		if i := re.SubexpIndex(name); i >= 0 {
+			return m[i]
+		}
+		return ""
+	}
+	t.Major, _ = strconv.Atoi(group("major"))
+	t.Minor, _ = strconv.Atoi(group("minor"))
+	t.Patch, _ = strconv.Atoi(group("patch"))
 	base := fmt.Sprintf("v%d.%d.%d", t.Major, t.Minor, t.Patch)
 	remainder := tag[len(base):]
 	if strings.Contains(remainder, ".") {
@@ -246,16 +288,24 @@ func ParseTag(tag string) *Tag {
 	} else {
 		t.Mode = ModeLegacy
 	}
-	if m[4] != "" {
-		t.StageName = strings.ToLower(m[4])
-		t.StagePad = len(m[5])
-		v, _ := strconv.Atoi(m[6])
+	if name := group("stage"); name != "" {
+		t.StageName = strings.ToLower(name)
+		t.StagePad = len(group("stagePadded"))
+		v, _ := strconv.Atoi(group("stageNum"))
 		t.Stage = &v
 	}
-	if m[7] != "" {
-		t.Pad = len(m[8])
-		v, _ := strconv.Atoi(m[9])
-		switch strings.ToLower(m[7]) {
+	if name := group("qualifier"); name != "" {
+		q, _ := p.lookup(name)
+		t.QualifierName = name
+		t.QualifierRank = q.Rank
+		t.QualifierPad = len(group("qualifierPadded"))
+		v, _ := strconv.Atoi(group("qualifierNum"))
+		t.Qualifier = &v
+	}
+	if name := group("env"); name != "" {
+		t.Pad = len(group("envPadded"))
+		v, _ := strconv.Atoi(group("envNum"))
+		switch strings.ToLower(name) {
 		case "test":
 			t.Test = &v
 		case "uat":
@@ -264,14 +314,33 @@ func ParseTag(tag string) *Tag {
 			return nil
 		}
 	}
-	if len(m) >= 11 && m[10] != "" {
-		v, _ := strconv.Atoi(m[10])
+	if r := group("release"); r != "" {
+		v, _ := strconv.Atoi(r)
 		t.Release = &v
 	}
 	return t
 }
 
-func (t *Tag) applyIncrement(flags CmdFlags) {
+// resetQualifier drops t's qualifier when command resets it, going by its
+// definition in qs.
+func (t *Tag) resetQualifier(command string, qs []Qualifier, w io.Writer) {
+	if t.Qualifier == nil {
+		return
+	}
+	if q, ok := findQualifier(qs, t.QualifierName); ok && !q.resetBy(command) {
+		explainf(w, "%s: kept because %s does not reset it", t.QualifierName, command)
+		return
+	}
+	explainf(w, "%s: dropped by %s", t.QualifierName, command)
+	t.QualifierName = ""
+	t.Qualifier = nil
+	t.QualifierPad = 0
+	t.QualifierRank = 0
+}
+
+// applyIncrement applies flags to t with the qualifiers defined in qs,
+// writing each decision to w when it is not nil.
+func (t *Tag) applyIncrement(flags CmdFlags, qs []Qualifier, w io.Writer) {
 	prevStage := t.Stage
 	prevStageName := strings.ToLower(t.StageName)
 	prevStagePad := t.StagePad
@@ -291,6 +360,8 @@ func (t *Tag) applyIncrement(flags CmdFlags) {
 		if flags.MajorValue != nil {
 			target = *flags.MajorValue
 		}
+		explainf(w, "major: %d -> %d, resetting minor, patch, stage, environment and release", t.Major, target)
+		t.resetQualifier("major", qs, w)
 		t.Major = target
 		t.Minor = 0
 		t.Patch = 0
@@ -310,6 +381,8 @@ func (t *Tag) applyIncrement(flags CmdFlags) {
 		if flags.MinorValue != nil {
 			target = *flags.MinorValue
 		}
+		explainf(w, "minor: %d -> %d, resetting patch, stage, environment and release", t.Minor, target)
+		t.resetQualifier("minor", qs, w)
 		t.Minor = target
 		t.Patch = 0
 		t.Release = nil
@@ -327,8 +400,15 @@ func (t *Tag) applyIncrement(flags CmdFlags) {
 		target := t.Patch
 		if flags.PatchValue != nil {
 			target = *flags.PatchValue
-		} else if (t.Test == nil || flags.Env != "") && (t.Uat == nil || flags.Env != "") && (t.Stage == nil || flags.Stage != "") {
+			explainf(w, "patch: %d -> %d as requested", t.Patch, target)
+		} else if (t.Test == nil || flags.Env != "") && (t.Uat == nil || flags.Env != "") && (t.Stage == nil || flags.Stage != "") && (t.QualifierRank >= 0 || flags.Qualifier != "") {
 			target = t.Patch + 1
+			explainf(w, "patch: %d -> %d", t.Patch, target)
+		} else {
+			explainf(w, "patch: stays %d because %s has a stage or environment and none was requested, so patch releases it", t.Patch, t)
+		}
+		if target != t.Patch || t.QualifierRank < 0 {
+			t.resetQualifier("patch", qs, w)
 		}
 		t.Patch = target
 		t.Stage = nil
@@ -345,21 +425,26 @@ func (t *Tag) applyIncrement(flags CmdFlags) {
 	if flags.Stage != "" {
 		stageName := strings.ToLower(flags.Stage)
 		stagePad := 2
+		padReason := "the default"
 		sameStage := prevStage != nil && prevStageName == stageName
 		if sameStage {
 			stagePad = prevStagePad
+			padReason = "kept from the previous tag"
 		}
 		if flags.StageDigits > 0 {
 			requestedPad := flags.StageDigits
 			if sameStage {
 				if requestedPad > stagePad {
 					stagePad = requestedPad
+					padReason = "widened by the number given"
 				}
 			} else {
 				if requestedPad > stagePad {
 					stagePad = requestedPad
+					padReason = "the width of the number given"
 				} else if requestedPad >= stagePad {
 					stagePad = requestedPad
+					padReason = "the width of the number given"
 				}
 				// otherwise keep the default width of 2 when starting a new stage with single digits
 			}
@@ -367,11 +452,22 @@ func (t *Tag) applyIncrement(flags CmdFlags) {
 		z := 1
 		if flags.StageValue != nil {
 			z = *flags.StageValue
+			explainf(w, "%s: set to %d as requested", stageName, z)
 		} else if prevStage != nil && prevStageName == stageName {
 			z = *prevStage + 1
+			explainf(w, "%s: %d -> %d", stageName, *prevStage, z)
 		} else if !flags.Major && !flags.Minor && !flags.Patch {
+			explainf(w, "patch: %d -> %d because %s starts a new stage without a major, minor or patch command", t.Patch, t.Patch+1, stageName)
 			t.Patch += 1
+			explainf(w, "%s: starting at 1", stageName)
+		} else {
+			explainf(w, "%s: starting at 1", stageName)
 		}
+		explainf(w, "%s: %d digits, %s", stageName, stagePad, padReason)
+		if prevEnv != nil {
+			explainf(w, "%s: dropped because the stage changed", prevEnvType)
+		}
+		t.resetQualifier("stage", qs, w)
 		t.Stage = ptr(z)
 		t.StagePad = stagePad
 		t.StageName = stageName
@@ -383,43 +479,105 @@ func (t *Tag) applyIncrement(flags CmdFlags) {
 		t.Release = nil
 	}
 
+	if flags.Qualifier != "" {
+		name := flags.Qualifier
+		q, _ := findQualifier(qs, name)
+		same := t.Qualifier != nil && t.QualifierName == name
+		pad := 0
+		padReason := "the default"
+		if same {
+			pad = t.QualifierPad
+			padReason = "kept from the previous tag"
+		}
+		if flags.QualifierDigits > pad {
+			pad = flags.QualifierDigits
+			padReason = "the width of the number given"
+		}
+		z := 1
+		switch {
+		case flags.QualifierValue != nil:
+			z = *flags.QualifierValue
+			explainf(w, "%s: set to %d as requested", name, z)
+		case same:
+			z = *t.Qualifier + 1
+			explainf(w, "%s: %d -> %d", name, *t.Qualifier, z)
+		case !flags.Major && !flags.Minor && !flags.Patch && flags.Stage == "" && q.Rank < t.QualifierRank:
+			// the new qualifier sorts lower, so it needs a new version
+			explainf(w, "patch: %d -> %d because %s would sort below %s", t.Patch, t.Patch+1, name, t)
+			t.Patch += 1
+			t.Stage = nil
+			t.StageName = ""
+			t.StagePad = 0
+			explainf(w, "%s: starting at 1", name)
+		default:
+			explainf(w, "%s: starting at 1", name)
+		}
+		explainf(w, "%s: %d digits, %s", name, pad, padReason)
+		if prevEnv != nil {
+			explainf(w, "%s: dropped because the %s changed", prevEnvType, name)
+		}
+		t.QualifierName = name
+		t.Qualifier = ptr(z)
+		t.QualifierPad = pad
+		t.QualifierRank = q.Rank
+		prevEnv = nil
+		prevEnvType = ""
+		prevPad = 0
+		t.Uat = nil
+		t.Test = nil
+		t.Release = nil
+	}
+
 	if flags.Env != "" {
 		envName := strings.ToLower(flags.Env)
 		envPad := 2
+		padReason := "the default"
 		sameEnv := prevEnv != nil && prevEnvType == envName
 		if sameEnv {
 			envPad = prevPad
+			padReason = "kept from the previous tag"
 		}
 		if flags.EnvDigits > 0 {
 			requestedPad := flags.EnvDigits
 			if sameEnv {
 				if requestedPad > envPad {
 					envPad = requestedPad
+					padReason = "widened by the number given"
 				}
 			} else {
 				if requestedPad > envPad {
 					envPad = requestedPad
+					padReason = "the width of the number given"
 				} else if requestedPad >= envPad {
 					envPad = requestedPad
+					padReason = "the width of the number given"
 				}
 				// otherwise keep the default width of 2 when starting a new environment with single digits
 			}
 		}
 		z := 1
+		step := "starting at 1"
 		if prevEnv != nil {
 			if prevEnvType == "uat" && envName == "uat" {
 				z = *prevEnv + 1
+				step = fmt.Sprintf("%d -> %d", *prevEnv, z)
 			} else if prevEnvType == "test" && envName == "test" {
 				z = *prevEnv + 1
+				step = fmt.Sprintf("%d -> %d", *prevEnv, z)
 			} else {
 				z = *prevEnv
+				step = fmt.Sprintf("keeps the counter %d of the previous %s tag", z, prevEnvType)
 			}
-		} else if !flags.Major && !flags.Minor && !flags.Patch && flags.Stage == "" && prevStage == nil {
+		} else if !flags.Major && !flags.Minor && !flags.Patch && flags.Stage == "" && prevStage == nil && t.Qualifier == nil {
+			explainf(w, "patch: %d -> %d because %s starts on a tag without a stage or environment", t.Patch, t.Patch+1, envName)
 			t.Patch += 1
 		}
 		if flags.EnvValue != nil {
 			z = *flags.EnvValue
+			step = fmt.Sprintf("set to %d as requested", z)
 		}
+		explainf(w, "%s: %s", envName, step)
+		explainf(w, "%s: %d digits, %s", envName, envPad, padReason)
 		t.Pad = envPad
 		if envName == "uat" {
 			t.Uat = ptr(z)
@@ -435,36 +593,94 @@ func (t *Tag) applyIncrement(flags CmdFlags) {
 		target := 1
 		if flags.ReleaseValue != nil {
 			target = *flags.ReleaseValue
+			explainf(w, "release: set to %d as requested", target)
 		} else if t.Release != nil {
 			target = *t.Release + 1
+			explainf(w, "release: %d -> %d", *t.Release, target)
+		} else {
+			explainf(w, "release: starting at 1")
 		}
 		t.Release = ptr(target)
 	}
 }
 
+// IncrementOptions controls IncrementWithOptions.
+type IncrementOptions struct {
+	// AllowBackwards lets numeric commands decrease counters.
+	AllowBackwards bool
+	// SkipForwards bumps the patch when numeric commands go backwards.
+	SkipForwards bool
+	// Transitions are the stages allowed to follow each stage of a
+	// pre-release. Nil means DefaultStageTransitions.
+	Transitions StageTransitions
+	// Force skips the stage transition check.
+	Force bool
+	// Qualifiers define the qualifiers the flags and the tag may name, see
+	// Parser.Qualifiers. A qualifier command not among them is an error.
+	Qualifiers []Qualifier
+	// Explain, when set, receives a line for each decision made.
+	Explain io.Writer
+}
+
+// Increment applies flags with the default stage transitions.
 func (t *Tag) Increment(flags CmdFlags, allowBackwards bool, skipForwards bool) error {
+	return t.IncrementWithOptions(flags, IncrementOptions{AllowBackwards: allowBackwards, SkipForwards: skipForwards})
+}
+
+// IncrementWithOptions applies flags to t. It fails, leaving t unchanged,
+// when the result would not be a higher tag or would move a pre-release to a
+// stage opts.Transitions does not allow.
+func (t *Tag) IncrementWithOptions(flags CmdFlags, opts IncrementOptions) error {
+	allowBackwards, skipForwards := opts.AllowBackwards, opts.SkipForwards
+	w := opts.Explain
 	original := t.Clone()
 	if original == nil {
 		return fmt.Errorf("no tag to increment")
 	}
+	if flags.Qualifier != "" {
+		if _, ok := findQualifier(opts.Qualifiers, flags.Qualifier); !ok {
+			return fmt.Errorf("unknown qualifier %q", flags.Qualifier)
+		}
+	}
+	if to := stageTransition(original, flags); to != "" {
+		if opts.Force {
+			explainf(w, "transition: %s -> %s not checked because of --force", original.StageName, to)
+		} else {
+			transitions := opts.Transitions
+			if transitions == nil {
+				transitions = DefaultStageTransitions
+			}
+			if err := checkTransition(original, flags, transitions); err != nil {
+				explainf(w, "transition: %s -> %s is not allowed", original.StageName, to)
+				return err
+			}
+			explainf(w, "transition: %s -> %s is allowed", original.StageName, to)
+		}
+	}
 
+	explainf(w, "applying %s to %s", flags, original)
 	currentFlags := flags
-	t.applyIncrement(currentFlags)
+	t.applyIncrement(currentFlags, opts.Qualifiers, w)
 
 	decreases := detectDecreases(original, t, currentFlags)
 	if len(decreases) == 0 {
 		if allowBackwards {
+			explainf(w, "result: %s", t)
 			return nil
 		}
 		if original.String() == t.String() {
 			newTag := t.String()
 			t.CopyFrom(original)
+			explainf(w, "result %s is unchanged", newTag)
 			return fmt.Errorf("resulting tag %s is unchanged from previous", newTag)
 		}
+		explainf(w, "result: %s", t)
 		return nil
 	}
+	explainf(w, "%s gives %s", formatDecreases(decreases), t)
 
 	if allowBackwards {
+		explainf(w, "result: %s, kept because of --allow-backwards", t)
 		return nil
 	}
 
@@ -474,11 +690,14 @@ func (t *Tag) Increment(flags CmdFlags, allowBackwards bool, skipForwards bool)
 		autoFlags.Patch = true
 		autoFlags.PatchValue = ptr(original.Patch + 1)
 		currentFlags = autoFlags
-		t.applyIncrement(currentFlags)
+		explainf(w, "skip-forwards: retrying with patch %d", original.Patch+1)
+		t.applyIncrement(currentFlags, opts.Qualifiers, w)
 		decreases = detectDecreases(original, t, currentFlags)
 		if len(decreases) == 0 {
+			explainf(w, "result: %s", t)
 			return nil
 		}
+		explainf(w, "skip-forwards: %s still", formatDecreases(decreases))
 	}
 
 	newTag := t.String()
@@ -494,7 +713,9 @@ type decrease struct {
 	current   int
 }
 
-func envInfo(tag *Tag) (string, *int) {
+// EnvInfo returns the environment of tag, "uat" or "test", and its counter,
+// or "" and nil when the tag has no environment.
+func EnvInfo(tag *Tag) (string, *int) {
 	if tag.Uat != nil {
 		return "uat", tag.Uat
 	}
@@ -536,13 +757,18 @@ func detectDecreases(original, current *Tag, flags CmdFlags) []decrease {
 	if flags.EnvValue != nil {
 		envName := strings.ToLower(flags.Env)
 		if envName != "" {
-			origEnvName, origEnvVal := envInfo(original)
-			currEnvName, currEnvVal := envInfo(current)
+			origEnvName, origEnvVal := EnvInfo(original)
+			currEnvName, currEnvVal := EnvInfo(current)
 			valid := baseSame && origEnvVal != nil && currEnvVal != nil && origEnvName == envName && currEnvName == envName
 			checkPtr(envName, origEnvVal, currEnvVal, flags.EnvValue, valid)
 		}
 	}
 
+	if flags.QualifierValue != nil {
+		valid := baseSame && original.QualifierName == flags.Qualifier && current.QualifierName == flags.Qualifier
+		checkPtr(flags.Qualifier, original.Qualifier, current.Qualifier, flags.QualifierValue, valid)
+	}
+
 	validRelease := baseSame && original.Release != nil && current.Release != nil
 	checkPtr("release", original.Release, current.Release, flags.ReleaseValue, validRelease)
 
@@ -0,0 +1,126 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// StageRelease names the final tag of a version, the one without a stage, in
+// StageTransitions.
+const StageRelease = "release"
+
+// stageNames are the stages StageTransitions may name, in rank order.
+var stageNames = []string{"alpha", "beta", "rc", "next", StageRelease}
+
+// StageTransitions lists, for each stage, the stages that may follow it
+// within the same version. A stage missing from the map may be followed by
+// anything.
+type StageTransitions map[string][]string
+
+// DefaultStageTransitions only lets a version move forwards through alpha,
+// beta, rc and next to its release.
+var DefaultStageTransitions = StageTransitions{
+	"alpha": {"alpha", "beta", "rc", "next", StageRelease},
+	"beta":  {"beta", "rc", "next", StageRelease},
+	"rc":    {"rc", "next", StageRelease},
+	"next":  {"next", StageRelease},
+}
+
+// Validate reports stages that StageTransitions does not know.
+func (st StageTransitions) Validate() error {
+	from := make([]string, 0, len(st))
+	for f := range st {
+		from = append(from, f)
+	}
+	sort.Strings(from)
+	for _, f := range from {
+		if !isStageName(f) || f == StageRelease {
+			return fmt.Errorf("unknown stage %q in transitions; expected one of %s", f, strings.Join(stageNames[:len(stageNames)-1], ", "))
+		}
+		for _, to := range st[f] {
+			if !isStageName(to) {
+				return fmt.Errorf("unknown stage %q after %s in transitions; expected one of %s", to, f, strings.Join(stageNames, ", "))
+			}
+		}
+	}
+	return nil
+}
+
+func isStageName(name string) bool {
+	for _, n := range stageNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Allowed reports whether to may follow from.
+func (st StageTransitions) Allowed(from, to string) bool {
+	allowed, ok := st[from]
+	if !ok {
+		return true
+	}
+	for _, a := range allowed {
+		if a == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionError is returned by Increment when the commands would move a
+// pre-release to a stage its transitions do not allow.
+type TransitionError struct {
+	Previous *Tag
+	From     string
+	To       string
+	Allowed  []string
+}
+
+func (e *TransitionError) Error() string {
+	allowed := "nothing"
+	if len(e.Allowed) > 0 {
+		allowed = strings.Join(e.Allowed, ", ")
+	}
+	return fmt.Sprintf("%s cannot be followed by %s (allowed after %s: %s); use --force or a major, minor or patch command to start a new version", e.Previous, e.To, e.From, allowed)
+}
+
+// stageTransition returns the stage flags move a pre-release to, or "" when
+// the tag has no stage or the commands start a new version themselves.
+// Without a stage command, patch releases the version instead of bumping it.
+func stageTransition(t *Tag, flags CmdFlags) string {
+	if t.Stage == nil || flags.Major || flags.Minor {
+		return ""
+	}
+	switch {
+	case flags.Patch && (flags.PatchValue != nil || flags.Stage != ""):
+		return ""
+	case flags.Patch:
+		return StageRelease
+	case flags.Stage != "":
+		return strings.ToLower(flags.Stage)
+	}
+	return ""
+}
+
+// checkTransition returns a *TransitionError when flags take t to a stage
+// transitions do not allow.
+func checkTransition(t *Tag, flags CmdFlags, transitions StageTransitions) error {
+	to := stageTransition(t, flags)
+	if to == "" {
+		return nil
+	}
+	from := strings.ToLower(t.StageName)
+	if transitions.Allowed(from, to) {
+		return nil
+	}
+	return &TransitionError{Previous: t.Clone(), From: from, To: to, Allowed: transitions[from]}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIncrementTransitions(t *testing.T) {
+	betaOnly := StageTransitions{"alpha": {"alpha", "beta"}, "beta": {"beta"}}
+	tests := []struct {
+		name        string
+		tag         string
+		cmds        []string
+		transitions StageTransitions
+		force       bool
+		want        string
+		wantErr     bool
+	}{
+		{name: "alpha after rc", tag: "v1.0.0-rc.03", cmds: []string{"alpha"}, wantErr: true},
+		{name: "beta after rc", tag: "v1.0.0-rc.03", cmds: []string{"beta"}, wantErr: true},
+		{name: "rc after rc", tag: "v1.0.0-rc.03", cmds: []string{"rc"}, want: "v1.0.0-rc.04"},
+		{name: "rc after beta", tag: "v1.0.0-beta.02", cmds: []string{"rc"}, want: "v1.0.1-rc.01"},
+		{name: "release after rc", tag: "v1.0.0-rc.03", cmds: []string{"patch"}, want: "v1.0.0"},
+		{name: "env keeps stage", tag: "v1.0.0-rc.03", cmds: []string{"test"}, want: "v1.0.0-rc.03.test.01"},
+		{name: "alpha after release", tag: "v1.0.0", cmds: []string{"alpha"}, want: "v1.0.1-alpha01"},
+		{name: "explicit patch starts a new version", tag: "v1.0.0-rc.03", cmds: []string{"patch", "alpha"}, want: "v1.0.1-alpha.01"},
+		{name: "minor starts a new version", tag: "v1.0.0-rc.03", cmds: []string{"minor", "alpha"}, want: "v1.1.0-alpha.01"},
+		{name: "force", tag: "v1.0.0-rc.03", cmds: []string{"alpha"}, force: true, want: "v1.0.1-alpha.01"},
+		{name: "configured", tag: "v1.0.0-alpha.01", cmds: []string{"beta"}, transitions: betaOnly, want: "v1.0.1-beta.01"},
+		{name: "configured rc", tag: "v1.0.0-alpha.01", cmds: []string{"rc"}, transitions: betaOnly, wantErr: true},
+		{name: "configured release", tag: "v1.0.0-beta.01", cmds: []string{"patch"}, transitions: betaOnly, wantErr: true},
+		{name: "unlisted stage", tag: "v1.0.0-rc.01", cmds: []string{"alpha"}, transitions: betaOnly, want: "v1.0.1-alpha.01"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag := ParseTag(tt.tag)
+			err := tag.IncrementWithOptions(CommandsToFlags(tt.cmds, "auto"), IncrementOptions{Transitions: tt.transitions, Force: tt.force})
+			if tt.wantErr {
+				var te *TransitionError
+				if !errors.As(err, &te) {
+					t.Fatalf("expected a TransitionError, got %v (%s)", err, tag)
+				}
+				if tag.String() != tt.tag {
+					t.Errorf("tag changed to %s on error", tag)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tag.String() != tt.want {
+				t.Errorf("got %s, want %s", tag, tt.want)
+			}
+		})
+	}
+}
+
+func TestStageTransitionsValidate(t *testing.T) {
+	if err := DefaultStageTransitions.Validate(); err != nil {
+		t.Errorf("default transitions: %v", err)
+	}
+	for _, st := range []StageTransitions{
+		{"gamma": {"beta"}},
+		{"alpha": {"gamma"}},
+		{StageRelease: {"alpha"}},
+	} {
+		if err := st.Validate(); err == nil {
+			t.Errorf("%v: expected an error", st)
+		}
+	}
+}
@@ -7,6 +7,7 @@
 package gittaginc
 
 import (
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -28,14 +29,51 @@ type CmdFlags struct {
 	Stage        string
 	StageValue   *int
 	StageDigits  int
-	Env          string
-	EnvValue     *int
-	EnvDigits    int
-	Valid        bool
-	Mode         string
+	// Qualifier is the name of a configured Qualifier to apply.
+	Qualifier       string
+	QualifierValue  *int
+	QualifierDigits int
+	Env             string
+	EnvValue        *int
+	EnvDigits       int
+	Valid           bool
+	Mode            string
 }
 
+// commandNames is every command name CommandsToFlags knows, in the order they
+// are documented.
+var commandNames = []string{"major", "minor", "patch", "release", "alpha", "beta", "rc", "next", "test", "uat"}
+
+// Commands returns the command names CommandsToFlags accepts in mode. Each
+// may be followed by a number.
+func Commands(mode string) []string {
+	return (*Parser)(nil).Commands(mode)
+}
+
+// Commands returns the command names p.CommandsToFlags accepts in mode,
+// including p's qualifiers.
+func (p *Parser) Commands(mode string) []string {
+	var names []string
+	for _, name := range commandNames {
+		if p.CommandsToFlags([]string{name}, mode).Valid {
+			names = append(names, name)
+		}
+	}
+	for _, q := range p.Qualifiers() {
+		names = append(names, q.Name)
+	}
+	return names
+}
+
+// CommandsToFlags reads the commands without qualifiers, see
+// Parser.CommandsToFlags.
 func CommandsToFlags(args []string, mode string) CmdFlags {
+	return (*Parser)(nil).CommandsToFlags(args, mode)
+}
+
+// CommandsToFlags reads the commands in args, which may name p's
+// qualifiers. Valid is false when one is unknown or given twice.
+func (p *Parser) CommandsToFlags(args []string, mode string) CmdFlags {
 	c := CmdFlags{Valid: true, Mode: mode}
 	re := regexp.MustCompile(`^([a-z]+)(\d+)?$`)
 	for _, f := range args {
@@ -110,9 +148,59 @@ func CommandsToFlags(args []string, mode string) CmdFlags {
 				c.EnvDigits = digits
 			}
 		default:
-			c.Valid = false
-			return c
+			if _, ok := p.lookup(name); !ok || c.Qualifier != "" {
+				c.Valid = false
+				return c
+			}
+			c.Qualifier = name
+			if value != nil {
+				c.QualifierValue = value
+				c.QualifierDigits = len(m[2])
+			}
 		}
 	}
 	return c
 }
+
+// Empty reports whether the flags hold no command.
+func (c CmdFlags) Empty() bool {
+	return !c.Major && !c.Minor && !c.Patch && !c.Release && c.Stage == "" && c.Qualifier == "" && c.Env == ""
+}
+
+// String writes the flags back as commands in their documented order, e.g.
+// "patch rc02 test".
+func (c CmdFlags) String() string {
+	var cmds []string
+	add := func(name string, value *int, digits int) {
+		if value != nil {
+			name += fmt.Sprintf("%0*d", digits, *value)
+		}
+		cmds = append(cmds, name)
+	}
+	if c.Major {
+		add("major", c.MajorValue, 0)
+	}
+	if c.Minor {
+		add("minor", c.MinorValue, 0)
+	}
+	if c.Patch {
+		if c.Mode == ModeArraneous {
+			add("release", c.PatchValue, 0)
+		} else {
+			add("patch", c.PatchValue, 0)
+		}
+	}
+	if c.Release {
+		add("release", c.ReleaseValue, 0)
+	}
+	if c.Stage != "" {
+		add(c.Stage, c.StageValue, c.StageDigits)
+	}
+	if c.Qualifier != "" {
+		add(c.Qualifier, c.QualifierValue, c.QualifierDigits)
+	}
+	if c.Env != "" {
+		add(c.Env, c.EnvValue, c.EnvDigits)
+	}
+	return strings.Join(cmds, " ")
+}
@@ -0,0 +1,30 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCommands(t *testing.T) {
+	for mode, want := range map[string]string{
+		"auto":        "major minor patch release alpha beta rc next test uat",
+		ModeSemver:    "major minor patch release alpha beta rc next test uat",
+		ModeArraneous: "major minor release alpha beta rc next test uat",
+	} {
+		got := Commands(mode)
+		if strings.Join(got, " ") != want {
+			t.Errorf("Commands(%s) got %v", mode, got)
+		}
+		for _, name := range got {
+			if !CommandsToFlags([]string{name + "2"}, mode).Valid {
+				t.Errorf("%s2 rejected in %s mode", name, mode)
+			}
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrBehindTags is returned by CheckVersionAgainstTags when a version is not
+// above every existing version tag.
+var ErrBehindTags = errors.New("version is not above the highest tag")
+
+// ReadVersionFile reads the version in path without qualifiers, see
+// Parser.ReadVersionFile.
+func ReadVersionFile(path string) (t *Tag, prefixed bool, err error) {
+	return (*Parser)(nil).ReadVersionFile(path)
+}
+
+// ReadVersionFile reads the version kept in path, such as a VERSION file,
+// with or without a leading v. prefixed reports whether it had one, so
+// WriteVersionFile can keep the file's style.
+func (p *Parser) ReadVersionFile(path string) (t *Tag, prefixed bool, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	s := strings.TrimSpace(string(b))
+	prefixed = strings.HasPrefix(s, "v")
+	if !prefixed {
+		s = "v" + s
+	}
+	if t = p.ParseTag(s); t == nil {
+		return nil, false, fmt.Errorf("%s: invalid version %q", path, strings.TrimSpace(string(b)))
+	}
+	return t, prefixed, nil
+}
+
+// WriteVersionFile replaces the version in path with t, followed by a
+// newline, with a leading v only when prefixed.
+func WriteVersionFile(path string, t *Tag, prefixed bool) error {
+	perm := os.FileMode(0o644)
+	if fi, err := os.Stat(path); err == nil {
+		perm = fi.Mode().Perm()
+	}
+	v := t.String()
+	if !prefixed {
+		v = strings.TrimPrefix(v, "v")
+	}
+	return os.WriteFile(path, []byte(v+"\n"), perm)
+}
+
+// CheckVersionAgainstTags makes sure t can be tagged: it returns
+// ErrTagExists when the tag is already in src and, unless allowBackwards,
+// ErrBehindTags when a version tag at or above it exists.
+func CheckVersionAgainstTags(src TagSource, t *Tag, mode string, allowBackwards bool) error {
+	if _, err := src.ResolveTag(t.String()); err == nil {
+		return fmt.Errorf("%s: %w", t, ErrTagExists)
+	} else if !errors.Is(err, ErrTagNotFound) {
+		return err
+	}
+	if allowBackwards {
+		return nil
+	}
+	highest, err := FindHighestVersionTag(src, mode)
+	if err != nil {
+		return err
+	}
+	if highest.Hash != "" && !highest.LessThan(t) {
+		return fmt.Errorf("%s is not above %s: %w", t, highest, ErrBehindTags)
+	}
+	return nil
+}
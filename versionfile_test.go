@@ -0,0 +1,75 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVersionFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "VERSION")
+	for _, tt := range []struct {
+		content  string
+		want     string
+		prefixed bool
+		written  string
+	}{
+		{"1.4.2\n", "v1.4.2", false, "1.4.3\n"},
+		{"  v1.4.2-rc.01\n\n", "v1.4.2-rc.01", true, "v1.4.3\n"},
+	} {
+		if err := os.WriteFile(path, []byte(tt.content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		v, prefixed, err := ReadVersionFile(path)
+		if err != nil || v.String() != tt.want || prefixed != tt.prefixed {
+			t.Errorf("%q: got %v %v %v", tt.content, v, prefixed, err)
+			continue
+		}
+		if err := WriteVersionFile(path, ParseTag("v1.4.3"), prefixed); err != nil {
+			t.Fatal(err)
+		}
+		b, _ := os.ReadFile(path)
+		if string(b) != tt.written {
+			t.Errorf("%q: wrote %q, want %q", tt.content, b, tt.written)
+		}
+		if fi, _ := os.Stat(path); fi.Mode().Perm() != 0o600 {
+			t.Errorf("permissions changed to %v", fi.Mode().Perm())
+		}
+	}
+
+	if err := os.WriteFile(path, []byte("one point two\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ReadVersionFile(path); err == nil {
+		t.Errorf("expected an invalid version to fail")
+	}
+}
+
+func TestCheckVersionAgainstTags(t *testing.T) {
+	src := NewMemoryTagSource("c1")
+	if err := CheckVersionAgainstTags(src, ParseTag("v0.0.1"), "auto", false); err != nil {
+		t.Errorf("no tags: %v", err)
+	}
+	if err := src.CreateTag("v1.2.0", "c0", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckVersionAgainstTags(src, ParseTag("v1.2.1"), "auto", false); err != nil {
+		t.Errorf("above the tags: %v", err)
+	}
+	if err := CheckVersionAgainstTags(src, ParseTag("v1.1.9"), "auto", false); !errors.Is(err, ErrBehindTags) {
+		t.Errorf("expected ErrBehindTags, got %v", err)
+	}
+	if err := CheckVersionAgainstTags(src, ParseTag("v1.1.9"), "auto", true); err != nil {
+		t.Errorf("allowing backwards: %v", err)
+	}
+	if err := CheckVersionAgainstTags(src, ParseTag("v1.2.0"), "auto", true); !errors.Is(err, ErrTagExists) {
+		t.Errorf("expected ErrTagExists, got %v", err)
+	}
+}
@@ -0,0 +1,249 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// WebhookEvent is sent in the X-Git-Tag-Inc-Event header and the
+	// payload's event field.
+	WebhookEvent = "tag_created"
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of the
+	// body, keyed with the hook's secret.
+	WebhookSignatureHeader = "X-Git-Tag-Inc-Signature"
+	// DefaultWebhookAttempts is how often a delivery is tried when the hook
+	// does not say.
+	DefaultWebhookAttempts = 3
+	// DefaultWebhookBackoff is the wait before the first retry, doubled for
+	// each one after.
+	DefaultWebhookBackoff = time.Second
+)
+
+// Webhook is an entry of the "webhooks" list of ConfigFile. It is called for
+// new tags whose environment is one of Environments and whose stage is one of
+// Stages; an empty list matches anything.
+type Webhook struct {
+	URL string `json:"url"`
+	// Environments are test, uat or none for tags without one.
+	Environments []string `json:"environments,omitempty"`
+	// Stages are alpha, beta, rc, next or release for tags without one.
+	Stages []string `json:"stages,omitempty"`
+	// SecretEnv names the environment variable holding the HMAC secret.
+	// Without it deliveries are not signed.
+	SecretEnv string `json:"secret_env,omitempty"`
+	// Attempts bounds the deliveries tried; zero means
+	// DefaultWebhookAttempts.
+	Attempts int `json:"attempts,omitempty"`
+}
+
+// ValidateWebhooks reports hooks without a usable URL or with unknown
+// environments or stages.
+func ValidateWebhooks(hooks []Webhook) error {
+	for i, h := range hooks {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("webhook %d: url %q is not an http or https URL", i+1, h.URL)
+		}
+		for _, env := range h.Environments {
+			if env != "test" && env != "uat" && env != "none" {
+				return fmt.Errorf("webhook %d: unknown environment %q; expected test, uat or none", i+1, env)
+			}
+		}
+		for _, stage := range h.Stages {
+			if !isStageName(stage) {
+				return fmt.Errorf("webhook %d: unknown stage %q; expected one of %s", i+1, stage, strings.Join(stageNames, ", "))
+			}
+		}
+		if h.Attempts < 0 {
+			return fmt.Errorf("webhook %d: attempts cannot be negative", i+1)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the hook is called for t.
+func (h Webhook) Matches(t *Tag) bool {
+	env, _ := EnvInfo(t)
+	if env == "" {
+		env = "none"
+	}
+	stage := StageRelease
+	if t.Stage != nil {
+		stage = strings.ToLower(t.StageName)
+	}
+	return matchesAny(h.Environments, env) && matchesAny(h.Stages, stage)
+}
+
+func matchesAny(list []string, s string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, l := range list {
+		if l == s {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookRepository identifies the repository in a WebhookPayload.
+type WebhookRepository struct {
+	Owner string `json:"owner,omitempty"`
+	Name  string `json:"name,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// WebhookTagger is who created the tag.
+type WebhookTagger struct {
+	Name  string    `json:"name"`
+	Email string    `json:"email"`
+	Date  time.Time `json:"date"`
+}
+
+// WebhookPayload is the JSON body posted to webhooks.
+type WebhookPayload struct {
+	Event       string            `json:"event"`
+	Tag         string            `json:"tag"`
+	Previous    string            `json:"previous"`
+	Commit      string            `json:"commit"`
+	Environment string            `json:"environment,omitempty"`
+	Stage       string            `json:"stage,omitempty"`
+	Tagger      *WebhookTagger    `json:"tagger,omitempty"`
+	Repository  WebhookRepository `json:"repository"`
+}
+
+// NewWebhookPayload describes the creation of t on commit. previous and
+// tagger may be nil.
+func NewWebhookPayload(previous, t *Tag, commit string, tagger *Signature, repo WebhookRepository) WebhookPayload {
+	env, _ := EnvInfo(t)
+	p := WebhookPayload{
+		Event:       WebhookEvent,
+		Tag:         t.String(),
+		Commit:      commit,
+		Environment: env,
+		Repository:  repo,
+	}
+	if previous != nil && previous.Hash != "" {
+		p.Previous = previous.String()
+	}
+	if t.Stage != nil {
+		p.Stage = strings.ToLower(t.StageName)
+	}
+	if tagger != nil {
+		p.Tagger = &WebhookTagger{Name: tagger.Name, Email: tagger.Email, Date: tagger.When}
+	}
+	return p
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for body.
+func SignWebhook(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookSender delivers payloads, retrying failed deliveries.
+type WebhookSender struct {
+	// HTTPClient defaults to http.DefaultClient.
+	HTTPClient *http.Client
+	// Backoff is the wait before the first retry; zero means
+	// DefaultWebhookBackoff.
+	Backoff time.Duration
+}
+
+// WebhookError is returned by WebhookSender.Send once every attempt failed.
+type WebhookError struct {
+	URL      string
+	Attempts int
+	Err      error
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook %s failed after %d attempt(s): %v", e.URL, e.Attempts, e.Err)
+}
+
+func (e *WebhookError) Unwrap() error {
+	return e.Err
+}
+
+// Send posts payload to the hook, signing it with secret when it is not
+// empty. Connection errors, 429 and 5xx responses are retried; other
+// responses outside 2xx are not.
+func (s *WebhookSender) Send(ctx context.Context, hook Webhook, secret string, payload WebhookPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	attempts := hook.Attempts
+	if attempts <= 0 {
+		attempts = DefaultWebhookAttempts
+	}
+	backoff := s.Backoff
+	if backoff <= 0 {
+		backoff = DefaultWebhookBackoff
+	}
+	attempt := 1
+	for ; ; attempt++ {
+		var retry bool
+		retry, err = s.post(ctx, hook.URL, secret, body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt == attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return &WebhookError{URL: hook.URL, Attempts: attempt, Err: ctx.Err()}
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	return &WebhookError{URL: hook.URL, Attempts: attempt, Err: err}
+}
+
+// post makes one delivery, reporting whether a failure is worth retrying.
+func (s *WebhookSender) post(ctx context.Context, target, secret string, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Git-Tag-Inc-Event", WebhookEvent)
+	if secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(secret, body))
+	}
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, errors.New(resp.Status)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2025, Arran Ubels
+// All rights reserved.
+//
+// This source code is licensed under the BSD-style license found in the
+// LICENSE file in the root directory of this source tree.
+
+package gittaginc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookMatches(t *testing.T) {
+	for _, tt := range []struct {
+		hook Webhook
+		tag  string
+		want bool
+	}{
+		{Webhook{}, "v1.0.0", true},
+		{Webhook{Environments: []string{"test", "uat"}}, "v1.0.0-uat.01", true},
+		{Webhook{Environments: []string{"test", "uat"}}, "v1.0.0", false},
+		{Webhook{Environments: []string{"none"}}, "v1.0.0-rc.01", true},
+		{Webhook{Stages: []string{"release"}}, "v1.0.0-test.01", true},
+		{Webhook{Stages: []string{"release"}}, "v1.0.0-rc.01.test.01", false},
+		{Webhook{Environments: []string{"uat"}, Stages: []string{"rc"}}, "v1.0.0-rc.01.uat.02", true},
+		{Webhook{Environments: []string{"uat"}, Stages: []string{"rc"}}, "v1.0.0-uat.02", false},
+	} {
+		if got := tt.hook.Matches(ParseTag(tt.tag)); got != tt.want {
+			t.Errorf("%+v on %s: got %v", tt.hook, tt.tag, got)
+		}
+	}
+}
+
+func TestValidateWebhooks(t *testing.T) {
+	if err := ValidateWebhooks([]Webhook{{URL: "https://example.com/hook", Environments: []string{"none"}, Stages: []string{"rc", "release"}}}); err != nil {
+		t.Error(err)
+	}
+	for _, bad := range []Webhook{
+		{URL: "example.com/hook"},
+		{URL: "ftp://example.com/hook"},
+		{URL: "https://example.com/hook", Environments: []string{"prod"}},
+		{URL: "https://example.com/hook", Stages: []string{"gamma"}},
+		{URL: "https://example.com/hook", Attempts: -1},
+	} {
+		if err := ValidateWebhooks([]Webhook{bad}); err == nil {
+			t.Errorf("%+v: expected an error", bad)
+		}
+	}
+}
+
+func TestWebhookSender(t *testing.T) {
+	var calls int
+	var got WebhookPayload
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if sig := r.Header.Get(WebhookSignatureHeader); sig != SignWebhook("secret", body) {
+			t.Errorf("unexpected signature %q", sig)
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		if err := json.Unmarshal(body, &got); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer srv.Close()
+
+	previous := ParseTag("v1.0.0-test.01")
+	previous.Hash = "abc"
+	tagger := &Signature{Name: "Test", Email: "test@example.com", When: time.Unix(0, 0).UTC()}
+	payload := NewWebhookPayload(previous, ParseTag("v1.0.0-uat.01"), "c1", tagger, WebhookRepository{Owner: "arran4", Name: "widget"})
+	s := &WebhookSender{Backoff: time.Millisecond}
+	if err := s.Send(context.Background(), Webhook{URL: srv.URL}, "secret", payload); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 attempts, got %d", calls)
+	}
+	if got.Event != WebhookEvent || got.Tag != "v1.0.0-uat.01" || got.Previous != "v1.0.0-test.01" || got.Commit != "c1" ||
+		got.Environment != "uat" || got.Tagger == nil || got.Tagger.Email != "test@example.com" || got.Repository.Name != "widget" {
+		t.Errorf("unexpected payload %+v", got)
+	}
+
+	calls = 0
+	err := s.Send(context.Background(), Webhook{URL: srv.URL, Attempts: 2}, "secret", payload)
+	var we *WebhookError
+	if !errors.As(err, &we) || we.Attempts != 2 || calls != 2 {
+		t.Errorf("expected 2 failed attempts, got %d calls and %v", calls, err)
+	}
+
+	gone := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer gone.Close()
+	calls = 0
+	if err := s.Send(context.Background(), Webhook{URL: gone.URL}, "", payload); err == nil || calls != 1 {
+		t.Errorf("expected a single failed attempt, got %d calls and %v", calls, err)
+	}
+}